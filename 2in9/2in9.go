@@ -15,22 +15,29 @@ func init() {
 }
 
 var Module = epaper.Module{
-	Ink,
-	Dimension,
-	lut,
-	command,
+	Ink: Ink,
+	Dim: Dimension,
+	Lut: lut,
+	Cmd: command,
+	SPI: Interface,
 }
 
 // Colors
 var Ink = epaper.Ink{
-	byte(0),
-	^byte(0),
+	COLORED:   byte(0),
+	UNCOLORED: ^byte(0),
 }
 
 // Display dimension
 var Dimension = epaper.Dim{
-	128,
-	296,
+	WIDTH:  128,
+	HEIGHT: 296,
+}
+
+// SPI interface, serial clock cycle for write is 100 ns at least
+var Interface = epaper.SPI{
+	SPEED: 10000000,
+	MODE:  0,
 }
 
 // commands
@@ -0,0 +1,107 @@
+/*
+Virtual display spanning multiple panels tiled next to each other
+*/
+package canvas
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/drahoslove/epaper"
+	eimage "github.com/drahoslove/epaper/image"
+)
+
+// Orientation tells how is the panel rotated on the canvas
+type Orientation int
+
+const (
+	Rotate0   Orientation = iota // top of the panel is up
+	Rotate90                     // panel is rotated clockwise, its top is on the right
+	Rotate180                    // panel is upside down
+	Rotate270                    // panel is rotated counterclockwise, its top is on the left
+)
+
+// Tile is single panel placed on the canvas
+type Tile struct {
+	Device      *epaper.Device
+	Offset      image.Point // top left corner of the tile on the canvas
+	Orientation Orientation
+}
+
+// Bounds returns area of the canvas covered by the tile
+func (t Tile) Bounds() image.Rectangle {
+	w, h := int(t.Device.WIDTH), int(t.Device.HEIGHT)
+	if t.Orientation == Rotate90 || t.Orientation == Rotate270 {
+		w, h = h, w
+	}
+	return image.Rect(0, 0, w, h).Add(t.Offset)
+}
+
+// Canvas combines multiple devices into one logical display
+type Canvas struct {
+	tiles []Tile
+}
+
+// New creates canvas from given tiles
+func New(tiles ...Tile) *Canvas {
+	return &Canvas{tiles}
+}
+
+// Bounds returns rectangle containing all the tiles
+func (c *Canvas) Bounds() image.Rectangle {
+	r := image.Rectangle{}
+	for _, t := range c.tiles {
+		r = r.Union(t.Bounds())
+	}
+	return r
+}
+
+// Split cuts image into bitmaps for each tile, rotated to the orientation of the panels.
+// Parts of tiles not covered by the image are white.
+func (c *Canvas) Split(m eimage.Mono) []eimage.Mono {
+	parts := make([]eimage.Mono, len(c.tiles))
+	for i, t := range c.tiles {
+		r := t.Bounds()
+		part := eimage.NewMono(image.Rect(0, 0, r.Dx(), r.Dy()))
+		part.Clear(color.White)
+		overlap := r.Intersect(m.Bounds())
+		for y := overlap.Min.Y; y < overlap.Max.Y; y++ {
+			for x := overlap.Min.X; x < overlap.Max.X; x++ {
+				part.Set(x-r.Min.X, y-r.Min.Y, m.At(x, y))
+			}
+		}
+		switch t.Orientation {
+		case Rotate90:
+			part.RotateLeft()
+		case Rotate180:
+			part.RotateLeft()
+			part.RotateLeft()
+		case Rotate270:
+			part.RotateRight()
+		}
+		parts[i] = part
+	}
+	return parts
+}
+
+// Display shows the image on all the tiles at once.
+// Frames are queued to all devices first, so the panels refresh in parallel.
+// It returns the first error reported by any of the devices.
+func (c *Canvas) Display(m eimage.Mono, update string) error {
+	done := make([]<-chan error, len(c.tiles))
+	for i, part := range c.Split(m) {
+		done[i] = c.tiles[i].Device.Submit(epaper.Frame{
+			Bitmap: part.Bitmap(),
+			Width:  part.Width(),
+			Height: part.Height(),
+			Update: update,
+		})
+	}
+	var err error
+	for _, d := range done {
+		if e := <-d; e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
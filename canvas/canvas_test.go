@@ -0,0 +1,120 @@
+package canvas
+
+import (
+	"image"
+	"image/color"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/drahoslove/epaper"
+	epd "github.com/drahoslove/epaper/2in9"
+	eimage "github.com/drahoslove/epaper/image"
+)
+
+// panel is fake transport which stays busy for a while after each refresh
+type panel struct {
+	mu       sync.Mutex
+	busyTill time.Time
+	shared   *concurrency
+}
+
+type concurrency struct {
+	mu      sync.Mutex
+	current int
+	max     int
+}
+
+func (p *panel) Command(cmd byte) error {
+	if cmd == epd.Module.Cmd.MASTER_ACTIVATION {
+		p.mu.Lock()
+		p.busyTill = time.Now().Add(50 * time.Millisecond)
+		p.mu.Unlock()
+		p.shared.mu.Lock()
+		p.shared.current++
+		if p.shared.current > p.shared.max {
+			p.shared.max = p.shared.current
+		}
+		p.shared.mu.Unlock()
+	}
+	return nil
+}
+
+func (p *panel) Data(data []byte) error { return nil }
+func (p *panel) Reset() error           { return nil }
+
+func (p *panel) Busy() (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.busyTill.IsZero() {
+		return false, nil
+	}
+	if time.Now().Before(p.busyTill) {
+		return true, nil
+	}
+	p.busyTill = time.Time{}
+	p.shared.mu.Lock()
+	p.shared.current--
+	p.shared.mu.Unlock()
+	return false, nil
+}
+
+func TestBounds(t *testing.T) {
+	c := New(
+		Tile{Device: epaper.New(epd.Module), Offset: image.Pt(0, 0)},
+		Tile{Device: epaper.New(epd.Module), Offset: image.Pt(128, 0)},
+		Tile{Device: epaper.New(epd.Module), Offset: image.Pt(0, 296), Orientation: Rotate90},
+	)
+	if b := c.Bounds(); b != image.Rect(0, 0, 296, 424) {
+		t.Errorf("bounds %v", b)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	c := New(
+		Tile{Device: epaper.New(epd.Module), Offset: image.Pt(0, 0)},
+		Tile{Device: epaper.New(epd.Module), Offset: image.Pt(128, 0), Orientation: Rotate90},
+		Tile{Device: epaper.New(epd.Module), Offset: image.Pt(0, 296), Orientation: Rotate180},
+	)
+	m := eimage.NewMono(c.Bounds())
+	m.Clear(color.White)
+	m.Set(0, 0, color.Black)     // top left of the first tile
+	m.Set(423, 0, color.Black)   // top right of the second tile
+	m.Set(127, 591, color.Black) // bottom right of the third tile
+
+	parts := c.Split(m)
+	for i, p := range parts {
+		if p.Width() != epd.Dimension.WIDTH || p.Height() != epd.Dimension.HEIGHT {
+			t.Errorf("tile %d is %dx%d", i, p.Width(), p.Height())
+		}
+		black := []image.Point{}
+		for y := 0; y < int(p.Height()); y++ {
+			for x := 0; x < int(p.Width()); x++ {
+				if p.At(x, y) == color.Black {
+					black = append(black, image.Pt(x, y))
+				}
+			}
+		}
+		if len(black) != 1 || black[0] != image.Pt(0, 0) {
+			t.Errorf("tile %d has black pixels at %v, expected top left corner only", i, black)
+		}
+	}
+}
+
+func TestDisplayParallel(t *testing.T) {
+	shared := &concurrency{}
+	tiles := []Tile{}
+	for i := 0; i < 3; i++ {
+		dev := epaper.New(epd.Module, epaper.WithTransport(&panel{shared: shared}))
+		defer dev.Close()
+		tiles = append(tiles, Tile{Device: dev, Offset: image.Pt(128*i, 0)})
+	}
+	c := New(tiles...)
+	m := eimage.NewMono(c.Bounds())
+	if err := c.Display(m, "full"); err != nil {
+		t.Fatal(err)
+	}
+	if shared.max != len(tiles) {
+		t.Errorf("%d panels were refreshing at once, expected %d", shared.max, len(tiles))
+	}
+}
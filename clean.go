@@ -0,0 +1,69 @@
+package epaper
+
+// CleanStep is single full refresh of the cleaning sequence
+type CleanStep int
+
+const (
+	CleanBlack  CleanStep = iota // whole display colored
+	CleanWhite                   // whole display uncolored
+	CleanInvert                  // inverted frame which was displayed before cleaning
+	CleanNoise                   // random noise
+)
+
+// DefaultCleanSequence is used by Clean unless WithCleanSequence is given
+var DefaultCleanSequence = []CleanStep{CleanInvert, CleanBlack, CleanWhite}
+
+// WithCleanSequence sets steps done in each cycle of Clean
+func WithCleanSequence(steps ...CleanStep) Option {
+	return func(d *Device) {
+		d.cleanSequence = steps
+	}
+}
+
+// Clean removes ghosting and image retention
+// by repeating the cleaning sequence of full refreshes given number of times.
+// Frame displayed before is shown again at the end and update mode is restored.
+func (d *Device) Clean(cycles int) error {
+	d.lock()
+	defer d.mu.Unlock()
+	d.wake()
+
+	steps := d.cleanSequence
+	if steps == nil {
+		steps = DefaultCleanSequence
+	}
+	frame := d.framebuffer()
+	original := append([]byte{}, frame...)
+	update := d.update
+	if update != "full" {
+		d.init("full")
+	}
+
+	for i := 0; i < cycles && d.err == nil; i++ {
+		for _, step := range steps {
+			switch step {
+			case CleanBlack:
+				d.fillFrame(d.Ink.COLORED, false)
+			case CleanWhite:
+				d.fillFrame(d.Ink.UNCOLORED, false)
+			case CleanInvert:
+				for j := range frame {
+					frame[j] = ^original[j]
+				}
+			case CleanNoise:
+				d.fillFrame(0, true)
+			}
+			d.writeWindow(0, 0, int(inBytes(d.WIDTH)), int(d.HEIGHT))
+			d.swapFrame()
+		}
+	}
+
+	copy(frame, original)
+	d.ramStale = false
+	d.writeWindow(0, 0, int(inBytes(d.WIDTH)), int(d.HEIGHT))
+	d.swapFrame()
+	if update != "full" && update != "" {
+		d.init(update)
+	}
+	return d.err
+}
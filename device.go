@@ -0,0 +1,290 @@
+package epaper
+
+import (
+	"sync"
+	"time"
+)
+
+// Device is a single e-paper display driven by the given Module specification
+type Device struct {
+	Module
+	transport   Transport
+	border      Border
+	idleTimeout time.Duration
+
+	mu        sync.Mutex // guards state and the bus
+	state     State
+	update    string // last used update mode
+	idleTimer *time.Timer
+	refresh   *Refresh // running refresh, nil if none
+	err       error    // error of the current operation
+	buf       []byte   // reused for data transfers
+	pending   int      // length of data in buf not sent yet
+	frame     []byte   // copy of controller RAM
+	frameFile string   // where to keep the frame between restarts
+	ramStale  bool     // frame was loaded from file and not written to RAM yet
+
+	cleanSequence []CleanStep
+	stats         stats
+	frames        frameSaver // displayed frame waiting to be saved to frameFile
+	log           Logger
+
+	qmu     sync.Mutex // guards the queue
+	queue   []job
+	closed  bool
+	wakeup  chan struct{} // signals worker about new jobs
+	stopped chan struct{} // closed when worker exits
+}
+
+// Option configures Device, pass it to New
+type Option func(*Device)
+
+// New creates Device for given epaper model
+func New(m Module, options ...Option) *Device {
+	d := &Device{
+		Module:    m,
+		transport: &rpioTransport{},
+		log:       nopLogger{},
+	}
+	for _, option := range options {
+		option(d)
+	}
+	return d
+}
+
+// Border selects what the border of the panel (VBD) does during refresh
+type Border int
+
+const (
+	BorderDefault Border = iota // not set, controller keeps its power-on value
+	BorderHold                  // HiZ - border is left floating
+	BorderVSS                   // fixed to VSS level
+	BorderBlack                 // follows black transition from LUT
+	BorderWhite                 // follows white transition from LUT
+)
+
+// values of BORDER_WAVEFORM_CONTROL register
+var borderWaveform = map[Border]byte{
+	BorderHold:  0xC0, // VBD = HiZ
+	BorderVSS:   0x40, // VBD = fix level, VSS
+	BorderBlack: 0x00, // VBD = GS transition, LUT0
+	BorderWhite: 0x01, // VBD = GS transition, LUT1
+}
+
+// WithBorder sets border waveform applied during each Init
+func WithBorder(b Border) Option {
+	return func(d *Device) {
+		d.border = b
+	}
+}
+
+// SetBorder changes border waveform and applies it immediately
+func (d *Device) SetBorder(b Border) {
+	d.lock()
+	defer d.mu.Unlock()
+	d.border = b
+	d.waitRefresh()
+	if d.state != StateOff && d.state != StateSleeping {
+		d.sendBorder()
+	}
+}
+
+func (d *Device) sendBorder() {
+	if d.border == BorderDefault {
+		return
+	}
+	d.sendCommand(d.Cmd.BORDER_WAVEFORM_CONTROL)
+	d.sendData(borderWaveform[d.border])
+}
+
+// Init resets the controller and prepares it for either "full" or "partial" update
+func (d *Device) Init(update string) {
+	d.lock()
+	defer d.mu.Unlock()
+	d.stopIdleTimer()
+	d.waitRefresh()
+	d.init(update)
+	d.scheduleSleep()
+}
+
+func (d *Device) init(update string) {
+	d.configureSPI()
+	d.reset()
+	d.sendCommand(d.Cmd.DRIVER_OUTPUT_CONTROL)
+	d.sendData(
+		byte((d.HEIGHT-1)&0xFF),
+		byte((d.HEIGHT-1)>>8),
+		0x00, // GD = 0; SM = 0; TB = 0;
+	)
+	d.sendCommand(d.Cmd.BOOSTER_SOFT_START_CONTROL)
+	// d.sendData(0xD7, 0xD6, 0x9D)
+	d.sendData(0xCF, 0xCE, 0x8D)
+	d.sendCommand(d.Cmd.WRITE_VCOM_REGISTER)
+	d.sendData(0x7c) // VCOM 7C // 8a
+	d.sendCommand(d.Cmd.SET_DUMMY_LINE_PERIOD)
+	d.sendData(0x1A) // 4 dummy lines per gate
+	d.sendCommand(d.Cmd.SET_GATE_TIME)
+	d.sendData(0x08) // 2us per line
+	d.sendBorder()
+	d.sendCommand(d.Cmd.DATA_ENTRY_MODE_SETTING)
+	d.sendData(0x03) // X increment Y increment
+	if update == "partial" {
+		d.setLut(d.Lut.PARTIAL)
+	}
+	if update == "full" {
+		d.setLut(d.Lut.FULL)
+	}
+	d.update = update
+	d.state = StateInitialized
+	d.log.Info("epaper: init", "update", update, "width", d.WIDTH, "height", d.HEIGHT)
+}
+
+func (d *Device) SetLut(lut []byte) {
+	d.lock()
+	defer d.mu.Unlock()
+	d.wake()
+	d.setLut(lut)
+	d.scheduleSleep()
+}
+
+func (d *Device) setLut(lut []byte) {
+	if d.logs() {
+		d.log.Debug("epaper: load lut", "length", len(lut))
+	}
+	d.sendCommand(d.Cmd.WRITE_LUT_REGISTER)
+	d.sendData(lut...)
+}
+
+func (d *Device) Clear(color byte) {
+	d.lock()
+	defer d.mu.Unlock()
+	d.wake()
+	d.fillFrame(color, false)
+	d.writeWindow(0, 0, int(inBytes(d.WIDTH)), int(d.HEIGHT))
+	d.swapFrame()
+}
+
+func (d *Device) Randomize() {
+	d.lock()
+	defer d.mu.Unlock()
+	d.wake()
+	d.fillFrame(0, true)
+	d.writeWindow(0, 0, int(inBytes(d.WIDTH)), int(d.HEIGHT))
+	d.swapFrame()
+}
+
+// Will display bitmap
+// if image is larger, it will be cropped
+// x does not need to be multiple of 8, surrounding pixels are kept as they were
+func (d *Device) Display(img []byte, x, y int, imgWidth, imgHeight uint) {
+	d.lock()
+	defer d.mu.Unlock()
+	if err := d.display(img, x, y, imgWidth, imgHeight); err != nil {
+		d.log.Error("epaper: display failed", "err", err)
+	}
+}
+
+func (d *Device) display(img []byte, x, y int, imgWidth, imgHeight uint) error {
+	if err := d.writeFrame(img, x, y, imgWidth, imgHeight); err != nil {
+		return err
+	}
+	d.swapFrame()
+	return d.err
+}
+
+// writeFrame writes bitmap into controller RAM,
+// bytes on the edges are merged with previous content if x is not multiple of 8
+func (d *Device) writeFrame(img []byte, x, y int, imgWidth, imgHeight uint) error {
+	if len(img) < int(imgHeight*inBytes(imgWidth)) {
+		return ErrBitmapTooSmall
+	}
+	d.wake()
+	left, top, right, bottom := d.mergeFrame(img, x, y, imgWidth, imgHeight)
+	if d.ramStale { // restore whole frame loaded from file
+		left, top, right, bottom = 0, 0, int(inBytes(d.WIDTH)), int(d.HEIGHT)
+		d.ramStale = false
+	}
+	if left < right && top < bottom {
+		d.writeWindow(left, top, right, bottom)
+	}
+	return d.err
+}
+
+// Will swap back frame with front frame and displays what's on it
+func (d *Device) SwapFrame() {
+	d.lock()
+	defer d.mu.Unlock()
+	d.wake()
+	d.swapFrame()
+}
+
+func (d *Device) swapFrame() {
+	r := d.startRefresh()
+	r.Wait()
+	if d.err == nil {
+		d.err = r.err
+	}
+	d.refresh = nil
+	d.idle()
+}
+
+func (d *Device) SetMemoryArea(x_start, y_start, x_end, y_end uint) {
+	d.lock()
+	defer d.mu.Unlock()
+	d.wake()
+	d.setMemoryArea(x_start, y_start, x_end, y_end)
+	d.scheduleSleep()
+}
+
+func (d *Device) setMemoryArea(x_start, y_start, x_end, y_end uint) {
+	d.sendCommand(d.Cmd.SET_RAM_X_ADDRESS_START_END_POSITION)
+	/* x point must be the multiple of 8 or the last 3 bits will be ignored */
+	d.sendData(byte(x_start >> 3))
+	d.sendData(byte(x_end >> 3))
+	d.sendCommand(d.Cmd.SET_RAM_Y_ADDRESS_START_END_POSITION)
+	d.sendData(byte(y_start))
+	d.sendData(byte(y_start >> 8))
+	d.sendData(byte(y_end))
+	d.sendData(byte(y_end >> 8))
+	d.waitUntilIdle()
+}
+
+func (d *Device) SetMemoryPointer(x, y uint) {
+	d.lock()
+	defer d.mu.Unlock()
+	d.wake()
+	d.setMemoryPointer(x, y)
+	d.scheduleSleep()
+}
+
+func (d *Device) setMemoryPointer(x, y uint) {
+	d.sendCommand(d.Cmd.SET_RAM_X_ADDRESS_COUNTER)
+	/* x point must be the multiple of 8 or the last 3 bits will be ignored */
+	d.sendData(byte(x >> 3))
+	d.sendCommand(d.Cmd.SET_RAM_Y_ADDRESS_COUNTER)
+	d.sendData(byte(y))
+	d.sendData(byte(y >> 8))
+	d.waitUntilIdle()
+}
+
+// Sleep puts the controller into deep sleep,
+// it will be woken up automatically by next drawing call
+func (d *Device) Sleep() {
+	d.lock()
+	defer d.mu.Unlock()
+	d.stopIdleTimer()
+	d.waitRefresh()
+	if d.state == StateOff || d.state == StateSleeping {
+		return
+	}
+	d.sleep()
+}
+
+func (d *Device) sleep() {
+	d.log.Info("epaper: deep sleep")
+	d.sendCommand(d.Cmd.DEEP_SLEEP_MODE)
+	d.sendData(1)
+	// d.waitUntilIdle()
+	d.state = StateSleeping
+	d.idleTimer = nil
+}
@@ -0,0 +1,826 @@
+package epaper_test
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/drahoslove/epaper"
+	epd "github.com/drahoslove/epaper/2in9"
+	eimage "github.com/drahoslove/epaper/image"
+)
+
+// recorder is Transport remembering everything sent to it
+type recorder struct {
+	max   int  // MaxTransfer, 0 means no limit
+	count bool // only count, do not keep data
+	ops   []op
+	bytes int
+	calls int
+	spi   epaper.SPI
+
+	commandsSent int
+}
+
+type op struct {
+	cmd  bool
+	data []byte
+}
+
+func (r *recorder) Command(cmd byte) error {
+	r.commandsSent++
+	if !r.count {
+		r.ops = append(r.ops, op{true, []byte{cmd}})
+	}
+	return nil
+}
+
+func (r *recorder) Data(data []byte) error {
+	r.calls++
+	r.bytes += len(data)
+	if !r.count {
+		r.ops = append(r.ops, op{false, append([]byte{}, data...)})
+	}
+	return nil
+}
+
+func (r *recorder) Busy() (bool, error) { return false, nil }
+func (r *recorder) Reset() error        { return nil }
+func (r *recorder) MaxTransfer() int    { return r.max }
+
+func (r *recorder) ConfigureSPI(speed uint32, mode uint8) error {
+	r.spi = epaper.SPI{SPEED: speed, MODE: mode}
+	return nil
+}
+
+// ramWrites returns data chunks sent after last WRITE_RAM command
+func (r *recorder) ramWrites() [][]byte {
+	var chunks [][]byte
+	for _, o := range r.ops {
+		if o.cmd {
+			if o.data[0] == epd.Module.Cmd.WRITE_RAM {
+				chunks = [][]byte{}
+			} else if chunks != nil {
+				return chunks
+			}
+			continue
+		}
+		if chunks != nil {
+			chunks = append(chunks, o.data)
+		}
+	}
+	return chunks
+}
+
+func frameSize() int {
+	return int(epd.Dimension.WIDTH/8) * int(epd.Dimension.HEIGHT)
+}
+
+func TestBorder(t *testing.T) {
+	cmd := epd.Module.Cmd.BORDER_WAVEFORM_CONTROL
+
+	r := &recorder{}
+	epaper.New(epd.Module, epaper.WithTransport(r)).Init("full")
+	if n := r.commands(cmd); n != 0 {
+		t.Errorf("border sent %d times, default should keep power-on value", n)
+	}
+
+	r = &recorder{}
+	dev := epaper.New(epd.Module, epaper.WithTransport(r), epaper.WithBorder(epaper.BorderWhite))
+	dev.Init("full")
+	if data := r.lastCommandData(cmd); !bytes.Equal(data, []byte{0x01}) {
+		t.Errorf("init sent border %X, expected 01", data)
+	}
+	dev.SetBorder(epaper.BorderHold)
+	if data := r.lastCommandData(cmd); !bytes.Equal(data, []byte{0xC0}) {
+		t.Errorf("set border sent %X, expected C0", data)
+	}
+
+	// sleeping controller gets the border with next init
+	dev.Sleep()
+	r.ops = nil
+	dev.SetBorder(epaper.BorderVSS)
+	if n := r.commands(cmd); n != 0 {
+		t.Errorf("border sent %d times to sleeping controller", n)
+	}
+	dev.Init("full")
+	if data := r.lastCommandData(cmd); !bytes.Equal(data, []byte{0x40}) {
+		t.Errorf("init sent border %X, expected 40", data)
+	}
+}
+
+// waitState polls the device until it gets into given state
+func waitState(t *testing.T, dev *epaper.Device, state epaper.State) {
+	t.Helper()
+	for start := time.Now(); dev.State() != state; time.Sleep(time.Millisecond) {
+		if time.Since(start) > time.Second {
+			t.Fatalf("device is %v, expected %v", dev.State(), state)
+		}
+	}
+}
+
+func TestAutoSleep(t *testing.T) {
+	r := &recorder{}
+	dev := epaper.New(epd.Module, epaper.WithTransport(r), epaper.WithAutoSleep(10*time.Millisecond))
+	dev.Init("partial")
+	waitState(t, dev, epaper.StateSleeping)
+	if n := r.commands(epd.Module.Cmd.DEEP_SLEEP_MODE); n != 1 {
+		t.Errorf("deep sleep sent %d times", n)
+	}
+
+	// next draw wakes the controller up with the same update mode
+	r.ops = nil
+	dev.Display([]byte{0x00}, 0, 0, 8, 1)
+	if err := dev.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n := r.commands(epd.Module.Cmd.DRIVER_OUTPUT_CONTROL); n != 1 {
+		t.Errorf("controller initialized %d times on wake up", n)
+	}
+	if lut := r.lastCommandData(epd.Module.Cmd.WRITE_LUT_REGISTER); !bytes.Equal(lut, epd.Module.Lut.PARTIAL) {
+		t.Error("partial lut not loaded on wake up")
+	}
+	waitState(t, dev, epaper.StateSleeping)
+}
+
+func TestAutoSleepKeepsErr(t *testing.T) {
+	l := &logRecorder{}
+	dev := epaper.New(epd.Module, epaper.WithTransport(&failingTransport{}),
+		epaper.WithLogger(l), epaper.WithAutoSleep(10*time.Millisecond))
+	dev.Init("full")
+	err := dev.Err()
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	waitState(t, dev, epaper.StateSleeping)
+	if dev.Err() != err {
+		t.Errorf("error of the last operation replaced by %v", dev.Err())
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if log := strings.Join(l.messages, "\n"); !strings.Contains(log, "ERROR epaper: auto sleep failed") {
+		t.Errorf("sleep error not logged in:\n%s", log)
+	}
+}
+
+// gate is recorder which stays busy once refresh is started until it is released
+type gate struct {
+	recorder
+	mu      sync.Mutex
+	armed   bool
+	once    sync.Once
+	entered chan struct{} // closed when refresh starts waiting
+	release chan struct{}
+}
+
+func newGate() *gate {
+	return &gate{entered: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (g *gate) Command(cmd byte) error {
+	if cmd == epd.Module.Cmd.MASTER_ACTIVATION {
+		g.mu.Lock()
+		g.armed = true
+		g.mu.Unlock()
+	}
+	return g.recorder.Command(cmd)
+}
+
+func (g *gate) Busy() (bool, error) {
+	g.mu.Lock()
+	armed := g.armed
+	g.mu.Unlock()
+	if armed {
+		g.once.Do(func() { close(g.entered) })
+		<-g.release
+	}
+	return false, nil
+}
+
+func TestSubmitSupersedes(t *testing.T) {
+	g := newGate()
+	dev := epaper.New(epd.Module, epaper.WithTransport(g))
+	defer dev.Close()
+
+	first := dev.Submit(epaper.Frame{Bitmap: []byte{0x00}, X: 8, Width: 8, Height: 1})
+	<-g.entered // worker is busy with the first frame
+	older := dev.Submit(epaper.Frame{Bitmap: []byte{0x00}, Width: 8, Height: 1})
+	other := dev.Submit(epaper.Frame{Bitmap: []byte{0x00}, Width: 8, Height: 1, Update: "partial"})
+	newer := dev.Submit(epaper.Frame{Bitmap: []byte{0xFF}, Width: 8, Height: 1})
+	if err := <-older; err != epaper.ErrSuperseded {
+		t.Errorf("older frame: %v, expected ErrSuperseded", err)
+	}
+	close(g.release)
+	for name, done := range map[string]<-chan error{"first": first, "other": other, "newer": newer} {
+		if err := <-done; err != nil {
+			t.Errorf("%s frame: %v", name, err)
+		}
+	}
+	if ram := g.lastCommandData(epd.Module.Cmd.WRITE_RAM); !bytes.Equal(ram, []byte{0xFF}) {
+		t.Errorf("last frame written %X, expected FF", ram)
+	}
+
+	small := dev.Submit(epaper.Frame{Bitmap: []byte{0x00}, Width: 16, Height: 1})
+	if err := <-small; err != epaper.ErrBitmapTooSmall {
+		t.Errorf("small bitmap: %v, expected ErrBitmapTooSmall", err)
+	}
+}
+
+func TestSubmitCopiesBitmap(t *testing.T) {
+	g := newGate()
+	dev := epaper.New(epd.Module, epaper.WithTransport(g))
+	defer dev.Close()
+
+	first := dev.Submit(epaper.Frame{Bitmap: []byte{0x00}, X: 8, Width: 8, Height: 1})
+	<-g.entered
+	buf := []byte{0x00}
+	queued := dev.Submit(epaper.Frame{Bitmap: buf, Width: 8, Height: 1})
+	buf[0] = 0xFF // reused by the caller while the frame is queued
+	close(g.release)
+	if err := <-first; err != nil {
+		t.Error(err)
+	}
+	if err := <-queued; err != nil {
+		t.Error(err)
+	}
+	if ram := g.lastCommandData(epd.Module.Cmd.WRITE_RAM); !bytes.Equal(ram, []byte{0x00}) {
+		t.Errorf("written %X, expected the bitmap as it was submitted", ram)
+	}
+}
+
+func TestCloseDrainsQueue(t *testing.T) {
+	g := newGate()
+	dev := epaper.New(epd.Module, epaper.WithTransport(g))
+
+	first := dev.Submit(epaper.Frame{Bitmap: []byte{0x00}, Width: 8, Height: 1})
+	<-g.entered
+	queued := dev.Submit(epaper.Frame{Bitmap: []byte{0x00}, X: 8, Width: 8, Height: 1})
+
+	closed := make(chan struct{})
+	go func() {
+		dev.Close()
+		close(closed)
+	}()
+	if err := <-queued; err != epaper.ErrClosed {
+		t.Errorf("queued frame: %v, expected ErrClosed", err)
+	}
+	select {
+	case <-closed:
+		t.Error("Close returned before the frame being displayed was finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(g.release)
+	<-closed
+	if err := <-first; err != nil {
+		t.Errorf("frame being displayed: %v", err)
+	}
+	if err := <-dev.Submit(epaper.Frame{Bitmap: []byte{0x00}, Width: 8, Height: 1}); err != epaper.ErrClosed {
+		t.Errorf("frame submitted after Close: %v, expected ErrClosed", err)
+	}
+}
+
+func TestDisplayAsync(t *testing.T) {
+	g := newGate()
+	dev := epaper.New(epd.Module, epaper.WithTransport(g))
+
+	r, err := dev.DisplayAsync([]byte{0x00}, 0, 0, 8, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-g.entered
+	select {
+	case <-r.Done():
+		t.Error("refresh done while the panel is busy")
+	default:
+	}
+
+	written := make(chan struct{})
+	go func() {
+		dev.Display([]byte{0xFF}, 8, 0, 8, 1)
+		close(written)
+	}()
+	select {
+	case <-written:
+		t.Error("RAM written while the panel is busy")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if n := g.commands(epd.Module.Cmd.WRITE_RAM); n != 1 {
+		t.Errorf("RAM written %d times during refresh, expected 1", n)
+	}
+
+	close(g.release)
+	<-written
+	if err := r.Err(); err != nil {
+		t.Error(err)
+	}
+	if n := g.commands(epd.Module.Cmd.WRITE_RAM); n != 2 {
+		t.Errorf("RAM written %d times, expected 2", n)
+	}
+}
+
+func TestSwapFrameAsync(t *testing.T) {
+	g := newGate()
+	dev := epaper.New(epd.Module, epaper.WithTransport(g))
+
+	r := dev.SwapFrameAsync()
+	<-g.entered
+	select {
+	case <-r.Done():
+		t.Error("refresh done while the panel is busy")
+	default:
+	}
+
+	swapped := make(chan struct{})
+	go func() {
+		dev.SwapFrame()
+		close(swapped)
+	}()
+	select {
+	case <-swapped:
+		t.Error("frame swapped while the panel is busy")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if n := g.commands(epd.Module.Cmd.MASTER_ACTIVATION); n != 1 {
+		t.Errorf("refresh started %d times while busy, expected 1", n)
+	}
+
+	close(g.release)
+	<-swapped
+	if err := r.Err(); err != nil {
+		t.Error(err)
+	}
+	if n := g.commands(epd.Module.Cmd.MASTER_ACTIVATION); n != 2 {
+		t.Errorf("refresh started %d times, expected 2", n)
+	}
+}
+
+func TestInitConfiguresSPI(t *testing.T) {
+	r := &recorder{}
+	epaper.New(epd.Module, epaper.WithTransport(r)).Init("full")
+	if r.spi != epd.Module.SPI {
+		t.Errorf("spi configured as %+v, expected %+v", r.spi, epd.Module.SPI)
+	}
+
+	slow := epaper.SPI{SPEED: 1000000, MODE: 0}
+	epaper.New(epd.Module, epaper.WithTransport(r), epaper.WithSPI(slow)).Init("full")
+	if r.spi != slow {
+		t.Errorf("spi configured as %+v, expected %+v", r.spi, slow)
+	}
+
+	mode3 := epaper.SPI{SPEED: epd.Module.SPI.SPEED, MODE: 3}
+	epaper.New(epd.Module, epaper.WithTransport(r), epaper.WithSPI(epaper.SPI{MODE: 3})).Init("full")
+	if r.spi != mode3 {
+		t.Errorf("spi configured as %+v, expected %+v", r.spi, mode3)
+	}
+}
+
+func TestClearChunks(t *testing.T) {
+	r := &recorder{max: 1000}
+	dev := epaper.New(epd.Module, epaper.WithTransport(r))
+	dev.Clear(0xFF)
+
+	sent := 0
+	for _, chunk := range r.ramWrites() {
+		if len(chunk) > r.max {
+			t.Errorf("chunk of %d bytes exceeds max transfer", len(chunk))
+		}
+		if !bytes.Equal(chunk, bytes.Repeat([]byte{0xFF}, len(chunk))) {
+			t.Error("chunk is not cleared")
+		}
+		sent += len(chunk)
+	}
+	if sent != frameSize() {
+		t.Errorf("%d bytes sent, expected %d", sent, frameSize())
+	}
+}
+
+func TestDisplayChunks(t *testing.T) {
+	r := &recorder{max: 1000}
+	dev := epaper.New(epd.Module, epaper.WithTransport(r))
+
+	img := make([]byte, frameSize())
+	for i := range img {
+		img[i] = byte(i)
+	}
+	dev.Display(img, 0, 0, epd.Dimension.WIDTH, epd.Dimension.HEIGHT)
+	if err := dev.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	chunks := r.ramWrites()
+	if n := (frameSize() + r.max - 1) / r.max; len(chunks) != n {
+		t.Errorf("frame sent in %d chunks, expected %d", len(chunks), n)
+	}
+	if sent := bytes.Join(chunks, nil); !bytes.Equal(sent, img) {
+		t.Error("sent data differ from bitmap")
+	}
+}
+
+func BenchmarkClear(b *testing.B) {
+	r := &recorder{count: true}
+	dev := epaper.New(epd.Module, epaper.WithTransport(r))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		dev.Clear(0xFF)
+	}
+	b.ReportMetric(float64(r.calls)/float64(b.N), "transfers/frame")
+}
+
+func BenchmarkDisplay(b *testing.B) {
+	r := &recorder{count: true}
+	dev := epaper.New(epd.Module, epaper.WithTransport(r))
+	img := make([]byte, frameSize())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		dev.Display(img, 0, 0, epd.Dimension.WIDTH, epd.Dimension.HEIGHT)
+	}
+	b.ReportMetric(float64(r.calls)/float64(b.N), "transfers/frame")
+}
+
+func TestDisplayAllocs(t *testing.T) {
+	dev := epaper.New(epd.Module, epaper.WithTransport(&recorder{count: true}))
+	img := make([]byte, frameSize())
+	n := testing.AllocsPerRun(50, func() {
+		dev.Display(img, 0, 0, epd.Dimension.WIDTH, epd.Dimension.HEIGHT)
+	})
+	// refresh handle and its goroutine account for most of them,
+	// the count varies slightly with scheduling
+	if n > 16 {
+		t.Errorf("%v allocations per frame", n)
+	}
+}
+
+// lastCommandData returns data sent after last occurence of the command
+func (r *recorder) lastCommandData(cmd byte) []byte {
+	data := []byte(nil)
+	for i, o := range r.ops {
+		if o.cmd && o.data[0] == cmd {
+			data = []byte{}
+			for _, o := range r.ops[i+1:] {
+				if o.cmd {
+					break
+				}
+				data = append(data, o.data...)
+			}
+		}
+	}
+	return data
+}
+
+func TestDisplayUnaligned(t *testing.T) {
+	sprite := []byte{0x00, 0x3F, 0x00, 0x3F} // 10x2 black
+	for _, tc := range []struct {
+		x, y  int
+		xArea []byte // x start and end in bytes
+		ram   []byte
+	}{
+		{0, 3, []byte{0, 1}, []byte{0x00, 0x3F, 0x00, 0x3F}},
+		{5, 3, []byte{0, 1}, []byte{0xF8, 0x01, 0xF8, 0x01}},
+		{13, 0, []byte{1, 2}, []byte{0xF8, 0x01, 0xF8, 0x01}},
+		{-3, -1, []byte{0, 0}, []byte{0x01}},
+		{123, 0, []byte{15, 15}, []byte{0xE0, 0xE0}},
+	} {
+		r := &recorder{}
+		dev := epaper.New(epd.Module, epaper.WithTransport(r))
+		dev.Clear(0xFF)
+		dev.Display(sprite, tc.x, tc.y, 10, 2)
+
+		if area := r.lastCommandData(epd.Module.Cmd.SET_RAM_X_ADDRESS_START_END_POSITION); !bytes.Equal(area, tc.xArea) {
+			t.Errorf("x=%d: x area %X, expected %X", tc.x, area, tc.xArea)
+		}
+		if ram := r.lastCommandData(epd.Module.Cmd.WRITE_RAM); !bytes.Equal(ram, tc.ram) {
+			t.Errorf("x=%d: ram %X, expected %X", tc.x, ram, tc.ram)
+		}
+	}
+}
+
+func TestFrameFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "frame")
+
+	r := &recorder{}
+	dev := epaper.New(epd.Module, epaper.WithTransport(r), epaper.WithFrameFile(path))
+	dev.Clear(0x00)
+	dev.Display([]byte{0xFF}, 8, 0, 8, 1)
+	if err := dev.Err(); err != nil {
+		t.Fatal(err)
+	}
+	dev.Close() // saves the frame right away
+
+	// after restart, the first write restores whole frame
+	r = &recorder{}
+	dev = epaper.New(epd.Module, epaper.WithTransport(r), epaper.WithFrameFile(path))
+	dev.Display([]byte{0xFF}, 0, 1, 8, 1)
+	ram := r.lastCommandData(epd.Module.Cmd.WRITE_RAM)
+	if len(ram) != frameSize() {
+		t.Fatalf("%d bytes written, expected whole frame", len(ram))
+	}
+	if ram[0] != 0x00 || ram[1] != 0xFF || ram[16] != 0xFF || ram[17] != 0x00 {
+		t.Errorf("restored frame starts with %X", ram[:18])
+	}
+
+	// next write is partial again
+	dev.Display([]byte{0xFF}, 0, 2, 8, 1)
+	if ram := r.lastCommandData(epd.Module.Cmd.WRITE_RAM); len(ram) != 1 {
+		t.Errorf("%d bytes written, expected 1", len(ram))
+	}
+}
+
+// waitFile polls until the file exists
+func waitFile(t *testing.T, path string) []byte {
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if data, err := ioutil.ReadFile(path); err == nil {
+			return data
+		}
+	}
+	t.Fatalf("%s not written", path)
+	return nil
+}
+
+func TestFrameFileRateLimited(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "frame")
+	dev := epaper.New(epd.Module, epaper.WithTransport(&recorder{}), epaper.WithFrameFile(path))
+	dev.Display([]byte{0x00}, 0, 0, 8, 1)
+	if data := waitFile(t, path); len(data) != 4+frameSize() || data[4] != 0x00 {
+		t.Fatalf("frame file of %d bytes", len(data))
+	}
+
+	dev.Display([]byte{0x0F}, 0, 0, 8, 1)
+	time.Sleep(20 * time.Millisecond)
+	if data, _ := ioutil.ReadFile(path); data[4] != 0x00 {
+		t.Errorf("frame file rewritten within the interval")
+	}
+	dev.Close()
+	if data, _ := ioutil.ReadFile(path); data[4] != 0x0F {
+		t.Errorf("last frame not saved on Close, file starts with %X", data[4])
+	}
+}
+
+// refreshFailing is recorder which fails busy wait of the refresh
+type refreshFailing struct {
+	recorder
+	refreshing bool
+}
+
+func (r *refreshFailing) Command(cmd byte) error {
+	if cmd == epd.Module.Cmd.MASTER_ACTIVATION {
+		r.refreshing = true
+	}
+	return r.recorder.Command(cmd)
+}
+
+func (r *refreshFailing) Busy() (bool, error) {
+	if r.refreshing {
+		return false, errors.New("busy pin stuck")
+	}
+	return false, nil
+}
+
+func TestFrameFileFailedRefresh(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "frame")
+	dev := epaper.New(epd.Module, epaper.WithTransport(&refreshFailing{}), epaper.WithFrameFile(path))
+	dev.Display([]byte{0x00}, 0, 0, 8, 1)
+	if dev.Err() == nil {
+		t.Error("expected error")
+	}
+	dev.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("frame of failed refresh saved")
+	}
+}
+
+func TestFrameFileWriteError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "frame")
+	l := &logRecorder{}
+	dev := epaper.New(epd.Module, epaper.WithTransport(&recorder{}), epaper.WithFrameFile(path), epaper.WithLogger(l))
+	dev.Display([]byte{0x00}, 0, 0, 8, 1)
+	if err := dev.Err(); err != nil {
+		t.Errorf("display failed with %v", err)
+	}
+	dev.Close()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if log := strings.Join(l.messages, "\n"); !strings.Contains(log, "ERROR epaper: saving frame failed") {
+		t.Errorf("write error not logged in:\n%s", log)
+	}
+}
+
+func TestFrameFileOtherDimension(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "frame")
+	if err := ioutil.WriteFile(path, []byte{0, 8, 0, 1, 0x00}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	r := &recorder{}
+	dev := epaper.New(epd.Module, epaper.WithTransport(r), epaper.WithFrameFile(path))
+	dev.Display([]byte{0x00}, 0, 0, 8, 1)
+	if ram := r.lastCommandData(epd.Module.Cmd.WRITE_RAM); len(ram) != 1 {
+		t.Errorf("%d bytes written, file should be ignored", len(ram))
+	}
+}
+
+// commands returns how many times was the command sent
+func (r *recorder) commands(cmd byte) int {
+	n := 0
+	for _, o := range r.ops {
+		if o.cmd && o.data[0] == cmd {
+			n++
+		}
+	}
+	return n
+}
+
+func TestClean(t *testing.T) {
+	r := &recorder{}
+	dev := epaper.New(epd.Module, epaper.WithTransport(r), epaper.WithCleanSequence(epaper.CleanInvert, epaper.CleanNoise))
+	dev.Init("partial")
+	dev.Clear(0xFF)
+	dev.Display([]byte{0x0F}, 0, 0, 8, 1)
+	r.ops = nil
+
+	if err := dev.Clean(2); err != nil {
+		t.Fatal(err)
+	}
+	if n := r.commands(epd.Module.Cmd.MASTER_ACTIVATION); n != 2*2+1 {
+		t.Errorf("%d refreshes, expected 5", n)
+	}
+	ram := r.lastCommandData(epd.Module.Cmd.WRITE_RAM)
+	if len(ram) != frameSize() || ram[0] != 0x0F || ram[1] != 0xFF {
+		t.Error("original frame is not restored")
+	}
+	if lut := r.lastCommandData(epd.Module.Cmd.WRITE_LUT_REGISTER); !bytes.Equal(lut, epd.Module.Lut.PARTIAL) {
+		t.Error("partial update is not restored")
+	}
+}
+
+func TestStats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats")
+	for run := 1; run <= 2; run++ {
+		r := &recorder{count: true}
+		dev := epaper.New(epd.Module, epaper.WithTransport(r), epaper.WithStatsFile(path))
+		dev.Init("full")
+		dev.Clear(0xFF)
+		dev.Init("partial")
+		dev.Display([]byte{0x00}, 0, 0, 8, 1)
+		dev.Display([]byte{0xFF}, 0, 0, 8, 1)
+
+		s := dev.Stats()
+		if s.Refreshes["full"] != 1 || s.Refreshes["partial"] != 2 {
+			t.Errorf("run %d: refreshes %v", run, s.Refreshes)
+		}
+		if s.LifetimeRefreshes["full"] != uint64(run) || s.LifetimeRefreshes["partial"] != uint64(2*run) {
+			t.Errorf("run %d: lifetime refreshes %v", run, s.LifetimeRefreshes)
+		}
+		if s.BytesSent < uint64(frameSize()) || s.BytesSent != uint64(r.bytes+r.commandsSent) {
+			t.Errorf("run %d: %d bytes sent, recorded %d", run, s.BytesSent, r.bytes+r.commandsSent)
+		}
+		if s.Errors != 0 {
+			t.Errorf("run %d: %d errors", run, s.Errors)
+		}
+		dev.Close() // saves counts of the refreshes after the first one
+	}
+}
+
+func TestStatsHandler(t *testing.T) {
+	dev := epaper.New(epd.Module, epaper.WithTransport(&recorder{count: true}))
+	dev.Clear(0xFF)
+
+	w := httptest.NewRecorder()
+	dev.StatsHandler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+	for _, line := range []string{
+		"# TYPE epaper_refreshes_total counter\n",
+		`epaper_refreshes_total{mode="full"} 1` + "\n",
+		"epaper_errors_total 0\n",
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("missing %q in:\n%s", line, body)
+		}
+	}
+}
+
+// logRecorder is Logger remembering messages by level
+type logRecorder struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *logRecorder) add(level, msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, level+" "+msg)
+}
+
+func (l *logRecorder) Debug(msg string, args ...interface{}) { l.add("DEBUG", msg) }
+func (l *logRecorder) Info(msg string, args ...interface{})  { l.add("INFO", msg) }
+func (l *logRecorder) Warn(msg string, args ...interface{})  { l.add("WARN", msg) }
+func (l *logRecorder) Error(msg string, args ...interface{}) { l.add("ERROR", msg) }
+
+type failingTransport struct {
+	recorder
+}
+
+func (failingTransport) Data(data []byte) error {
+	return errors.New("broken bus")
+}
+
+func TestLogger(t *testing.T) {
+	l := &logRecorder{}
+	dev := epaper.New(epd.Module, epaper.WithTransport(&recorder{}), epaper.WithLogger(l))
+	dev.Init("full")
+	dev.Display([]byte{0x00}, 0, 0, 8, 1)
+
+	log := strings.Join(l.messages, "\n")
+	for _, msg := range []string{
+		"INFO epaper: init",
+		"DEBUG epaper: load lut",
+		"DEBUG epaper: write window",
+		"DEBUG epaper: busy wait",
+	} {
+		if !strings.Contains(log, msg) {
+			t.Errorf("missing %q in:\n%s", msg, log)
+		}
+	}
+
+	l = &logRecorder{}
+	dev = epaper.New(epd.Module, epaper.WithTransport(&failingTransport{}), epaper.WithLogger(l))
+	dev.Init("full")
+	if dev.Err() == nil {
+		t.Error("expected error")
+	}
+	if log := strings.Join(l.messages, "\n"); !strings.Contains(log, "ERROR epaper: operation failed") {
+		t.Errorf("error not logged in:\n%s", log)
+	}
+
+	l = &logRecorder{}
+	dev = epaper.New(epd.Module, epaper.WithTransport(&recorder{}), epaper.WithLogger(l))
+	dev.Display([]byte{0x00}, 0, 0, 16, 1)
+	if log := strings.Join(l.messages, "\n"); !strings.Contains(log, "ERROR epaper: display failed") {
+		t.Errorf("display error not logged in:\n%s", log)
+	}
+}
+
+// ramReader is recorder able to read RAM filled with 0xAA, preceded by dummy byte
+type ramReader struct {
+	recorder
+}
+
+func (r *ramReader) ReadData(data []byte) error {
+	data[0] = 0x00
+	for i := range data[1:] {
+		data[1+i] = 0xAA
+	}
+	return nil
+}
+
+func TestReadRAM(t *testing.T) {
+	dev := epaper.New(epd.Module, epaper.WithTransport(&ramReader{}))
+	if _, err := dev.ReadRAM(); err != epaper.ErrNotSupported {
+		t.Errorf("expected ErrNotSupported, got %v", err)
+	}
+
+	module := epd.Module
+	module.Cmd.READ_RAM = 0x27
+	r := &ramReader{}
+	dev = epaper.New(module, epaper.WithTransport(r))
+	bitmap, err := dev.ReadRAM()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bitmap) != 4+frameSize() || !bytes.Equal(bitmap[:4], []byte{0, 128, 1, 40}) {
+		t.Fatalf("bitmap of %d bytes starting with %X", len(bitmap), bitmap[:4])
+	}
+	if !bytes.Equal(bitmap[4:], bytes.Repeat([]byte{0xAA}, frameSize())) {
+		t.Error("dummy byte should be skipped")
+	}
+	m, err := eimage.Parse(bitmap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Bounds() != image.Rect(0, 0, 128, 296) || m.At(0, 0) != color.White || m.At(1, 0) != color.Black {
+		t.Errorf("parsed as %v image starting with %v, %v", m.Bounds(), m.At(0, 0), m.At(1, 0))
+	}
+
+	// unaligned write is merged with content read
+	dev.Display([]byte{0x00}, 4, 0, 4, 1)
+	if ram := r.lastCommandData(module.Cmd.WRITE_RAM); !bytes.Equal(ram, []byte{0xA0}) {
+		t.Errorf("ram %X, expected A0", ram)
+	}
+}
+
+func TestReadRAMAutoSleep(t *testing.T) {
+	module := epd.Module
+	module.Cmd.READ_RAM = 0x27
+	dev := epaper.New(module, epaper.WithTransport(&ramReader{}), epaper.WithAutoSleep(10*time.Millisecond))
+	if _, err := dev.ReadRAM(); err != nil {
+		t.Fatal(err)
+	}
+	waitState(t, dev, epaper.StateSleeping)
+}
@@ -23,7 +23,9 @@ func TestReset(t *testing.T) {
 		// Display(bitmap, 0, 0, model.Spec.Res.WIDTH, model.Spec.Res.HEIGHT)
 	}
 
-	epaper.Setup()
+	if err := epaper.Setup(); err != nil {
+		t.Fatal(err)
+	}
 	defer epaper.Teardown()
 
 	filename := os.Getenv("FILE")
@@ -3,38 +3,120 @@ package main
 // sudo GOPATH=/home/pi/go /usr/local/go/bin/go test -v
 
 import (
+	"bytes"
 	"flag"
+	goimage "image"
 	"io/ioutil"
+	"log/slog"
 	"net/http"
+	"os"
+	"time"
 
 	"github.com/drahoslove/epaper"
 	epd "github.com/drahoslove/epaper/2in9"
 	"github.com/drahoslove/epaper/image"
+	"github.com/drahoslove/epaper/patterns"
 )
 
 func main() {
-	epaper.Setup()
+	logger := slog.Default()
+	if err := epaper.Setup(); err != nil {
+		logger.Error("setup failed", "err", err)
+		os.Exit(1)
+	}
 	defer epaper.Teardown()
 
-	displayBitmap := func(m image.Mono) {
-		epaper.Display(m.Bitmap(), 0, 0, m.Width(), m.Height())
+	dev := epaper.New(epd.Module, epaper.WithLogger(logger))
+	defer dev.Close()
+
+	displayBitmap := func(m image.Mono, update string) error {
+		return <-dev.Submit(epaper.Frame{
+			Bitmap: m.Bitmap(),
+			Width:  m.Width(),
+			Height: m.Height(),
+			Update: update,
+		})
+	}
+
+	// accepts Mono files as well as raw Mono data without magic bytes,
+	// grayscale formats like PGM are converted by fixed threshold
+	decode := func(data []byte) (image.Mono, error) {
+		img, _, err := goimage.Decode(bytes.NewReader(data))
+		if err == goimage.ErrFormat {
+			return image.Parse(data)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if m, ok := img.(image.Mono); ok {
+			return m, nil
+		}
+		b := img.Bounds()
+		if b.Dx() > 0xFFFF || b.Dy() > 0xFFFF {
+			return nil, image.ErrSize
+		}
+		m := image.NewMono(b.Sub(b.Min))
+		image.Threshold{}.Draw(m, m.Bounds(), img, b.Min)
+		return m, nil
 	}
 
 	filename := flag.String("file", "", "bitmap file to show")
 	mode := flag.String("mode", "full", "refresh mode 'full' or 'partial'")
 	port := flag.String("port", "", "port on which to listen for incomming bitmaps, eg. '6969'")
 	clr := flag.Bool("clr", false, "clears display")
+	pattern := flag.String("pattern", "", "test pattern to show: 'card', 'checker', 'grid' or 'gradient'")
+	clean := flag.Int("clean", 0, "number of cleaning cycles to run at start and on schedule")
+	cleanEvery := flag.Duration("clean-every", 0, "runs cleaning periodically, eg. '24h'")
 
 	flag.Parse()
 
-	epaper.Init(*mode)
+	dev.Init(*mode)
 
-	println("FILE", *filename)
-	println("MODE", *mode)
-	println("SERVE", *port)
+	logger.Info("bitmapper", "file", *filename, "mode", *mode, "serve", *port)
 
 	if *clr {
-		epaper.Clear(epd.Ink.UNCOLORED)
+		dev.Clear(epd.Ink.UNCOLORED)
+	}
+
+	if *clean > 0 {
+		if err := dev.Clean(*clean); err != nil {
+			logger.Error("clean failed", "err", err)
+		}
+	}
+
+	if *cleanEvery > 0 {
+		cycles := *clean
+		if cycles == 0 {
+			cycles = 1
+		}
+		go func() {
+			for range time.Tick(*cleanEvery) {
+				if err := dev.Clean(cycles); err != nil {
+					logger.Error("clean failed", "err", err)
+				}
+			}
+		}()
+	}
+
+	if *pattern != "" {
+		var m image.Mono
+		switch *pattern {
+		case "card":
+			m = patterns.Card(epd.Dimension)
+		case "checker":
+			m = patterns.Checkerboard(epd.Dimension, 8)
+		case "grid":
+			m = patterns.Grid(epd.Dimension, 8)
+		case "gradient":
+			m = patterns.Gradient(epd.Dimension)
+		default:
+			logger.Warn("unknown pattern", "pattern", *pattern)
+		}
+		if m != nil {
+			if err := displayBitmap(m, ""); err != nil {
+				logger.Error("display failed", "err", err)
+			}
+		}
 	}
 
 	if *filename != "" {
@@ -42,33 +124,41 @@ func main() {
 		if err != nil {
 			panic(err)
 		}
-		displayBitmap(fileContent)
+		m, err := decode(fileContent)
+		if err != nil {
+			panic(err)
+		}
+		if err := displayBitmap(m, ""); err != nil {
+			logger.Error("display failed", "err", err)
+		}
 	}
 
 	if *port != "" {
-		lock := make(chan bool, 1)
-		http.HandleFunc("/epd/full", func(w http.ResponseWriter, r *http.Request) {
-			lock <- true
-			epaper.Init("full")
-			bodyContent, err := ioutil.ReadAll(r.Body)
-			if err != nil {
-				println(err)
+		serve := func(update string) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("Access-Control-Allow-Origin", "*")
+				bodyContent, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					logger.Error("read failed", "err", err)
+				}
+				m, err := decode(bodyContent)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				err = displayBitmap(m, update)
+				if err == epaper.ErrSuperseded {
+					w.WriteHeader(http.StatusConflict)
+				} else if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+				}
 			}
-			displayBitmap(bodyContent)
-			w.Header().Add("Access-Control-Allow-Origin", "*")
-			epaper.Init("partial")
-			<-lock
-		})
-		http.HandleFunc("/epd/partial", func(w http.ResponseWriter, r *http.Request) {
-			lock <- true
-			bodyContent, err := ioutil.ReadAll(r.Body)
-			if err != nil {
-				println(err)
-			}
-			displayBitmap(bodyContent)
-			w.Header().Add("Access-Control-Allow-Origin", "*")
-			<-lock
-		})
+		}
+		http.HandleFunc("/epd/full", serve("full"))
+		http.HandleFunc("/epd/partial", serve("partial"))
+		http.Handle("/metrics", dev.StatsHandler())
 		http.ListenAndServe(":"+*port, nil)
+	} else if *cleanEvery > 0 {
+		select {} // keep cleaning
 	}
 }
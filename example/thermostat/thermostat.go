@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"log"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/drahoslove/epaper"
@@ -19,6 +20,7 @@ const zeroK = float32(-273.15)
 
 var (
 	tempIndex = 0
+	tempsMu   sync.Mutex // guards temps and tempIndex
 )
 
 func main() {
@@ -41,26 +43,36 @@ func main() {
 		}
 	}
 
+	if err := epaper.Setup(); err != nil {
+		log.Fatal(err)
+	}
+	defer epaper.Teardown()
+	dev := epaper.New(epd.Module)
+	defer dev.Close()
+	dev.Init("full")
+	dev.Clear(255)
+	dev.Clear(255)
+	dev.Init("partial")
+
 	go func() {
-		epaper.Setup()
-		defer epaper.Teardown()
-		epaper.Init("full")
-		epaper.Clear(255)
-		epaper.Clear(255)
-		epaper.Init("partial")
 		for t := range time.Tick(time.Second * 1) {
-			render(shortNames, temps, t)
+			dev.Submit(render(shortNames, temps, t))
 		}
 	}()
 
 	for _ = range time.Tick(time.Second * 5) {
+		current := make([]float32, len(names))
 		for i, name := range names {
-			temp := readTemp(name)
-			// fmt.Println(time.Now().String()[11:22], shortNames[i], temp)
+			current[i] = readTemp(name)
+			// fmt.Println(time.Now().String()[11:22], shortNames[i], current[i])
+		}
+		tempsMu.Lock()
+		for i, temp := range current {
 			temps[i][tempIndex] = temp
 		}
 		tempIndex++
 		tempIndex %= 6
+		tempsMu.Unlock()
 	}
 }
 
@@ -79,10 +91,12 @@ func readTemp(devName string) float32 {
 	return temp
 }
 
-func render(names []string, temps [][6]float32, t time.Time) {
+func render(names []string, temps [][6]float32, t time.Time) epaper.Frame {
+	tempsMu.Lock()
+	defer tempsMu.Unlock()
+	update := "partial"
 	if t.Second() == 0 && t.Minute()%2 == 1 {
-		epaper.Init("full")
-		defer epaper.Init("partial")
+		update = "full"
 	}
 	irect := image.Rect(0, 0, int(epd.Dimension.HEIGHT), int(epd.Dimension.WIDTH))
 	img := eimage.NewMono(irect)
@@ -102,8 +116,12 @@ func render(names []string, temps [][6]float32, t time.Time) {
 
 	img.RotateRight()
 	img.DrawString(color.White, t.String()[11:19], 28, image.Pt(5, 280))
-	epaper.Display(img.Bitmap(), 0, 0, img.Width(), img.Height())
-	// epaper.Sleep()
+	return epaper.Frame{
+		Bitmap: img.Bitmap(),
+		Width:  img.Width(),
+		Height: img.Height(),
+		Update: update,
+	}
 }
 
 func renderProgress(img eimage.Mono, color color.Color, pos image.Point, temps [6]float32) {
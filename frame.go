@@ -0,0 +1,120 @@
+package epaper
+
+import (
+	"math/rand"
+)
+
+// framebuffer returns copy of what was written to controller RAM,
+// before the first write it is loaded from frame file or assumed to be cleared
+func (d *Device) framebuffer() []byte {
+	if n := int(inBytes(d.WIDTH) * d.HEIGHT); len(d.frame) != n {
+		d.frame = make([]byte, n)
+		if d.loadFrame(d.frame) {
+			d.ramStale = true // shown on display, but not in RAM of the controller
+			return d.frame
+		}
+		for i := range d.frame {
+			d.frame[i] = d.Ink.UNCOLORED
+		}
+	}
+	return d.frame
+}
+
+// fillFrame sets whole framebuffer to color, or random noise if random is set
+func (d *Device) fillFrame(color byte, random bool) {
+	frame := d.framebuffer()
+	d.ramStale = false
+	if random {
+		rand.Read(frame)
+		return
+	}
+	for i := range frame {
+		frame[i] = color
+	}
+}
+
+// mergeFrame copies bitmap to the framebuffer at given position,
+// x does not need to be multiple of 8, parts outside of the display are cropped.
+// It returns window which was changed, in bytes horizontally and in rows vertically,
+// the window is empty if bitmap lies outside of the display.
+func (d *Device) mergeFrame(img []byte, x, y int, imgWidth, imgHeight uint) (left, top, right, bottom int) {
+	frame := d.framebuffer()
+	stride := int(inBytes(d.WIDTH))
+	imgStride := int(inBytes(imgWidth))
+
+	x0, y0 := x, y
+	x1, y1 := x+int(imgWidth), y+int(imgHeight)
+	if x0 < 0 {
+		x0 = 0
+	}
+	if y0 < 0 {
+		y0 = 0
+	}
+	if x1 > int(d.WIDTH) {
+		x1 = int(d.WIDTH)
+	}
+	if y1 > int(d.HEIGHT) {
+		y1 = int(d.HEIGHT)
+	}
+	if x0 >= x1 || y0 >= y1 {
+		return 0, 0, 0, 0
+	}
+	left, right = x0/8, (x1+7)/8
+
+	for py := y0; py < y1; py++ {
+		src := img[(py-y)*imgStride : (py-y+1)*imgStride]
+		dst := frame[py*stride : (py+1)*stride]
+		for bx := left; bx < right; bx++ {
+			mask := byte(0xFF)
+			if bx*8 < x0 { // left edge
+				mask &= 0xFF >> uint(x0-bx*8)
+			}
+			if bx*8+8 > x1 { // right edge
+				mask &= 0xFF << uint(bx*8+8-x1)
+			}
+			dst[bx] = dst[bx]&^mask | bitsAt(src, bx*8-x)&mask
+		}
+	}
+	return left, y0, right, y1
+}
+
+// bitsAt returns 8 pixels of the row starting at given bit offset,
+// pixels outside of the row are 0
+func bitsAt(row []byte, offset int) byte {
+	i := offset >> 3 // rounds down for negative offset too
+	shift := uint(offset & 7)
+	hi, lo := uint16(0), uint16(0)
+	if i >= 0 && i < len(row) {
+		hi = uint16(row[i])
+	}
+	if i+1 >= 0 && i+1 < len(row) {
+		lo = uint16(row[i+1])
+	}
+	return byte((hi<<8 | lo) << shift >> 8)
+}
+
+// writeWindow sends part of the framebuffer to controller RAM,
+// window is given in bytes horizontally and in rows vertically
+func (d *Device) writeWindow(left, top, right, bottom int) {
+	frame := d.framebuffer()
+	stride := int(inBytes(d.WIDTH))
+	if d.logs() {
+		d.log.Debug("epaper: write window",
+			"x", left*8, "y", top,
+			"width", (right-left)*8, "height", bottom-top,
+			"bytes", (right-left)*(bottom-top),
+		)
+	}
+
+	d.setMemoryArea(uint(left*8), uint(top), uint(right*8-1), uint(bottom-1))
+	d.setMemoryPointer(uint(left*8), uint(top))
+	d.sendCommand(d.Cmd.WRITE_RAM)
+	if left == 0 && right == stride { // whole rows are continuous
+		d.streamData(frame[top*stride : bottom*stride])
+	} else {
+		for row := top; row < bottom; row++ {
+			d.streamData(frame[row*stride+left : row*stride+right])
+		}
+	}
+	d.flushData()
+}
@@ -0,0 +1,175 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"golang.org/x/image/math/f64"
+)
+
+// Transform is affine transformation of the plane, it maps x, y to
+//
+//	T[0]*x + T[1]*y + T[2], T[3]*x + T[4]*y + T[5]
+//
+// Transformations are combined by methods in the order they are applied:
+//
+//	// rotate by 30° around 100, 50
+//	t := Translation(-100, -50).Rotate(math.Pi / 6).Translate(100, 50)
+type Transform f64.Aff3
+
+// Identity returns transformation which keeps all points in place
+func Identity() Transform {
+	return Transform{1, 0, 0, 0, 1, 0}
+}
+
+// Translation returns transformation which moves points by dx, dy
+func Translation(dx, dy float64) Transform {
+	return Transform{1, 0, dx, 0, 1, dy}
+}
+
+// Rotation returns transformation which rotates points around 0, 0 by angle in radians,
+// positive angle rotates clockwise on the display as y axis points down
+func Rotation(angle float64) Transform {
+	sin, cos := math.Sincos(angle)
+	return Transform{cos, -sin, 0, sin, cos, 0}
+}
+
+// Scaling returns transformation which scales points from 0, 0 by sx, sy
+func Scaling(sx, sy float64) Transform {
+	return Transform{sx, 0, 0, 0, sy, 0}
+}
+
+// Shearing returns transformation which shifts x by kx*y and y by ky*x
+func Shearing(kx, ky float64) Transform {
+	return Transform{1, kx, 0, ky, 1, 0}
+}
+
+// Then returns transformation which applies t first and u after it
+func (t Transform) Then(u Transform) Transform {
+	return Transform{
+		u[0]*t[0] + u[1]*t[3], u[0]*t[1] + u[1]*t[4], u[0]*t[2] + u[1]*t[5] + u[2],
+		u[3]*t[0] + u[4]*t[3], u[3]*t[1] + u[4]*t[4], u[3]*t[2] + u[4]*t[5] + u[5],
+	}
+}
+
+// Translate returns t followed by translation
+func (t Transform) Translate(dx, dy float64) Transform {
+	return t.Then(Translation(dx, dy))
+}
+
+// Rotate returns t followed by rotation around 0, 0
+func (t Transform) Rotate(angle float64) Transform {
+	return t.Then(Rotation(angle))
+}
+
+// Scale returns t followed by scaling from 0, 0
+func (t Transform) Scale(sx, sy float64) Transform {
+	return t.Then(Scaling(sx, sy))
+}
+
+// Shear returns t followed by shearing
+func (t Transform) Shear(kx, ky float64) Transform {
+	return t.Then(Shearing(kx, ky))
+}
+
+// Invert returns transformation which maps points back,
+// false is returned if t collapses the plane into line or point
+func (t Transform) Invert() (Transform, bool) {
+	det := t[0]*t[4] - t[1]*t[3]
+	if det == 0 {
+		return Transform{}, false
+	}
+	a, b, d, e := t[4]/det, -t[1]/det, -t[3]/det, t[0]/det
+	return Transform{a, b, -a*t[2] - b*t[5], d, e, -d*t[2] - e*t[5]}, true
+}
+
+// Apply returns transformed point
+func (t Transform) Apply(x, y float64) (float64, float64) {
+	return t[0]*x + t[1]*y + t[2], t[3]*x + t[4]*y + t[5]
+}
+
+// Bounds returns smallest rectangle containing transformed r
+func (t Transform) Bounds(r image.Rectangle) image.Rectangle {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range []image.Point{r.Min, {r.Max.X, r.Min.Y}, {r.Min.X, r.Max.Y}, r.Max} {
+		x, y := t.Apply(float64(p.X), float64(p.Y))
+		minX, minY = math.Min(minX, x), math.Min(minY, y)
+		maxX, maxY = math.Max(maxX, x), math.Max(maxY, y)
+	}
+	const eps = 1e-9 // rounding errors of sin and cos
+	return image.Rect(
+		int(math.Floor(minX+eps)), int(math.Floor(minY+eps)),
+		int(math.Ceil(maxX-eps)), int(math.Ceil(maxY-eps)),
+	)
+}
+
+// Filter selects how source pixels are sampled by Affine
+type Filter int
+
+const (
+	FilterNearest     Filter = iota // color of the nearest source pixel
+	FilterSupersample               // mean brightness of Samples*Samples points within the pixel
+)
+
+// Affine draws images transformed by affine transformation into black and white images
+type Affine struct {
+	Filter    Filter
+	Samples   int       // samples per axis for FilterSupersample, 0 means 4
+	Luminance Luminance // Average if not set
+}
+
+// Transform draws part sr of src transformed by t (from src to dst coordinates) into dst.
+//
+// Pixels of dst not covered by transformed sr are kept,
+// pixels on the edges are blended with them when supersampled.
+func (a Affine) Transform(dst draw.Image, t Transform, src image.Image, sr image.Rectangle) {
+	sr = sr.Intersect(src.Bounds())
+	inv, ok := t.Invert()
+	if !ok || sr.Empty() {
+		return
+	}
+	dr := t.Bounds(sr).Intersect(dst.Bounds())
+
+	n := 1
+	if a.Filter == FilterSupersample {
+		n = a.Samples
+		if n == 0 {
+			n = 4
+		}
+	}
+	d := asView(dst)
+	for y := dr.Min.Y; y < dr.Max.Y; y++ {
+		for x := dr.Min.X; x < dr.Max.X; x++ {
+			sum, covered := 0.0, 0
+			for j := 0; j < n; j++ {
+				for i := 0; i < n; i++ {
+					// samples are in the middle of n*n sub-pixels
+					sx, sy := inv.Apply(float64(x)+(float64(i)+0.5)/float64(n), float64(y)+(float64(j)+0.5)/float64(n))
+					p := image.Pt(int(math.Floor(sx)), int(math.Floor(sy)))
+					if !p.In(sr) {
+						continue
+					}
+					sum += a.Luminance.Gray(src.At(p.X, p.Y))
+					covered++
+				}
+			}
+			if covered == 0 {
+				continue
+			}
+			if covered < n*n && isWhite(dst.At(x, y)) {
+				sum += float64(n*n - covered)
+			}
+			white := sum/float64(n*n) >= 0.5
+			if d != nil {
+				d.SetBit(x, y, white)
+			} else if white {
+				dst.Set(x, y, color.White)
+			} else {
+				dst.Set(x, y, color.Black)
+			}
+		}
+	}
+}
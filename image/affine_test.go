@@ -0,0 +1,91 @@
+package image
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	if x, y := Rotation(math.Pi/2).Apply(1, 0); math.Abs(x) > 1e-9 || math.Abs(y-1) > 1e-9 {
+		t.Errorf("rotated 1,0 to %v,%v", x, y)
+	}
+	tr := Translation(-10, 5).Rotate(0.3).Scale(2, 0.5).Shear(0.1, -0.2).Translate(7, 8)
+	inv, ok := tr.Invert()
+	if !ok {
+		t.Fatal("not invertible")
+	}
+	x, y := inv.Apply(tr.Apply(3, -4))
+	if math.Abs(x-3) > 1e-9 || math.Abs(y+4) > 1e-9 {
+		t.Errorf("inverted to %v,%v", x, y)
+	}
+	if _, ok := Scaling(1, 0).Invert(); ok {
+		t.Errorf("singular transformation inverted")
+	}
+	if b := Rotation(math.Pi / 2).Bounds(image.Rect(0, 0, 10, 4)); b != image.Rect(-4, 0, 0, 10) {
+		t.Errorf("bounds %v", b)
+	}
+}
+
+func TestAffineRotateRight(t *testing.T) {
+	src := randomMono(image.Rect(0, 0, 29, 13), 5)
+	want := append(Mono(nil), src...)
+	want.RotateRight()
+
+	got := NewMono(image.Rect(0, 0, 13, 29))
+	Affine{}.Transform(&got, Rotation(math.Pi/2).Translate(13, 0), src, src.Bounds())
+	for y := 0; y < 29; y++ {
+		for x := 0; x < 13; x++ {
+			if got.Bit(x, y) != want.Bit(x, y) {
+				t.Fatalf("pixel %d,%d differs", x, y)
+			}
+		}
+	}
+}
+
+func TestAffineScale(t *testing.T) {
+	src := NewMono(image.Rect(0, 0, 2, 1))
+	src.SetBit(1, 0, true)
+	dst := NewMono(image.Rect(0, 0, 8, 3))
+	Affine{}.Transform(&dst, Scaling(3, 2).Translate(1, 0), src, src.Bounds())
+	if want := []byte{0, 8, 0, 3, 0x0E, 0x0E, 0}; !bytes.Equal(dst, want) {
+		t.Errorf("got %X, want %X", []byte(dst), want)
+	}
+}
+
+func TestAffineSupersample(t *testing.T) {
+	square := image.NewUniform(color.White)
+	sr := image.Rect(0, 0, 40, 40)
+	for _, filter := range []Filter{FilterNearest, FilterSupersample} {
+		dst := NewMono(image.Rect(0, 0, 80, 80))
+		tr := Translation(-20, -20).Rotate(math.Pi/4).Translate(40, 40)
+		Affine{Filter: filter}.Transform(&dst, tr, square, sr)
+		if n := countWhite(dst); n < 1600-40 || n > 1600+40 {
+			t.Errorf("filter %d: %d white pixels of rotated square", filter, n)
+		}
+		if dst.Bit(40, 40) != true || dst.Bit(40, 10) != false || dst.Bit(40, 70) != false {
+			t.Errorf("filter %d: square misplaced", filter)
+		}
+	}
+
+	// gray is thresholded after averaging
+	dst := NewMono(image.Rect(0, 0, 8, 8))
+	Affine{Filter: FilterSupersample}.Transform(&dst, Scaling(0.5, 0.5), checker(16), image.Rect(0, 0, 16, 16))
+	if n := countWhite(dst); n != 64 {
+		t.Errorf("%d white pixels of downscaled checkerboard", n)
+	}
+}
+
+func checker(size int) image.Image {
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if (x+y)%2 == 0 {
+				img.SetGray(x, y, color.Gray{0xFF})
+			}
+		}
+	}
+	return img
+}
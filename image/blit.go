@@ -0,0 +1,120 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// RasterOp selects how pixels of source are combined with pixels of destination by Blit,
+// white pixel is 1 and black is 0
+type RasterOp int
+
+const (
+	RasterCopy             RasterOp = iota // dst = src
+	RasterAnd                              // dst = dst AND src
+	RasterOr                               // dst = dst OR src
+	RasterXor                              // dst = dst XOR src
+	RasterAndNot                           // dst = dst AND NOT src
+	RasterTransparentWhite                 // white pixels of src are transparent, black are drawn
+	RasterTransparentBlack                 // black pixels of src are transparent, white are drawn
+)
+
+// apply combines 8 pixels of dst with 8 pixels of src
+func (op RasterOp) apply(d, s byte) byte {
+	switch op {
+	case RasterAnd, RasterTransparentWhite:
+		return d & s
+	case RasterOr, RasterTransparentBlack:
+		return d | s
+	case RasterXor:
+		return d ^ s
+	case RasterAndNot:
+		return d &^ s
+	}
+	return s
+}
+
+// Blit combines pixels of src within sr with pixels of dst at dp by given raster operation.
+//
+// Rows of Mono and MonoView are processed by whole bytes for any bit offset
+// of source and destination, even when both share the same bitmap.
+// Other images are processed pixel by pixel.
+func Blit(dst draw.Image, dp image.Point, src image.Image, sr image.Rectangle, op RasterOp) {
+	clipped := sr.Intersect(src.Bounds())
+	dp = dp.Add(clipped.Min.Sub(sr.Min))
+	dr := clipped.Add(dp.Sub(clipped.Min)).Intersect(dst.Bounds())
+	sr = dr.Add(clipped.Min.Sub(dp))
+	if dr.Empty() {
+		return
+	}
+
+	d, s := asView(dst), asView(src)
+	if d == nil || s == nil {
+		for y := 0; y < dr.Dy(); y++ {
+			for x := 0; x < dr.Dx(); x++ {
+				var db, sb byte
+				if isWhite(dst.At(dr.Min.X+x, dr.Min.Y+y)) {
+					db = 1
+				}
+				if isWhite(src.At(sr.Min.X+x, sr.Min.Y+y)) {
+					sb = 1
+				}
+				if op.apply(db, sb)&1 == 1 {
+					dst.Set(dr.Min.X+x, dr.Min.Y+y, color.White)
+				} else {
+					dst.Set(dr.Min.X+x, dr.Min.Y+y, color.Black)
+				}
+			}
+		}
+		return
+	}
+	blit(d.m, dr.Add(d.delta), s.m, sr.Min.Add(s.delta), op)
+}
+
+// blit combines bitmaps, dr is already clipped and in coordinates of dst
+func blit(dst Mono, dr image.Rectangle, src Mono, sp image.Point, op RasterOp) {
+	dstStride, srcStride := dst.stride(), src.stride()
+	first, last := dr.Min.X/8, (dr.Max.X-1)/8
+	shift := sp.X - dr.Min.X // source bit for destination bit x is x+shift
+
+	// go backwards if source would be overwritten before it is read
+	y0, y1, yi := 0, dr.Dy(), 1
+	j0, j1, ji := first, last+1, 1
+	if sameBitmap(dst, src) {
+		if dr.Min.Y > sp.Y {
+			y0, y1, yi = dr.Dy()-1, -1, -1
+		}
+		if dr.Min.Y == sp.Y && shift < 0 {
+			j0, j1, ji = last, first-1, -1
+		}
+	}
+	for y := y0; y != y1; y += yi {
+		drow := dst[4+(dr.Min.Y+y)*dstStride:][:dstStride]
+		srow := src[4+(sp.Y+y)*srcStride:][:srcStride]
+		for j := j0; j != j1; j += ji {
+			mask := byte(0xFF)
+			if j == first {
+				mask &= 0xFF >> uint(dr.Min.X%8)
+			}
+			if j == last {
+				mask &= 0xFF << uint(7-(dr.Max.X-1)%8)
+			}
+			s := byteAt(srow, j*8+shift)
+			drow[j] = drow[j]&^mask | op.apply(drow[j], s)&mask
+		}
+	}
+}
+
+// byteAt returns 8 bits of row starting at bit p, bits outside of row are 0
+func byteAt(row []byte, p int) byte {
+	i, s := p>>3, uint(p&7) // rounds down for negative p too
+	var hi, lo byte
+	if i >= 0 && i < len(row) {
+		hi = row[i]
+	}
+	if i+1 >= 0 && i+1 < len(row) {
+		lo = row[i+1]
+	}
+	return hi<<s | lo>>(8-s)
+}
@@ -0,0 +1,96 @@
+package image
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+func randomMono(r image.Rectangle, seed int64) Mono {
+	m := NewMono(r)
+	rand.New(rand.NewSource(seed)).Read(m.Bitmap())
+	return m
+}
+
+// blitPixels is reference implementation of Blit
+func blitPixels(dst Mono, dp image.Point, src Mono, sr image.Rectangle, op RasterOp) {
+	for y := sr.Min.Y; y < sr.Max.Y; y++ {
+		for x := sr.Min.X; x < sr.Max.X; x++ {
+			px, py := dp.X+x-sr.Min.X, dp.Y+y-sr.Min.Y
+			if !image.Pt(x, y).In(src.Bounds()) || !image.Pt(px, py).In(dst.Bounds()) {
+				continue
+			}
+			var d, s byte
+			if dst.Bit(px, py) {
+				d = 1
+			}
+			if src.Bit(x, y) {
+				s = 1
+			}
+			dst.SetBit(px, py, op.apply(d, s)&1 == 1)
+		}
+	}
+}
+
+var rasterOps = []RasterOp{
+	RasterCopy, RasterAnd, RasterOr, RasterXor, RasterAndNot,
+	RasterTransparentWhite, RasterTransparentBlack,
+}
+
+func TestBlit(t *testing.T) {
+	src := randomMono(image.Rect(0, 0, 37, 9), 1)
+	for _, op := range rasterOps {
+		for sx := -2; sx < 10; sx++ {
+			for dx := -3; dx < 12; dx++ {
+				sr := image.Rect(sx, 1, sx+23, 8)
+				dp := image.Pt(dx, 2)
+				got, want := randomMono(image.Rect(0, 0, 29, 7), 2), randomMono(image.Rect(0, 0, 29, 7), 2)
+				Blit(&got, dp, src, sr, op)
+				blitPixels(want, dp, src, sr, op)
+				if !bytes.Equal(got, want) {
+					t.Fatalf("op %d from %v to %v: got %X, want %X", op, sr, dp, []byte(got), []byte(want))
+				}
+			}
+		}
+	}
+}
+
+func TestBlitOverlap(t *testing.T) {
+	for _, op := range rasterOps {
+		for _, d := range []image.Point{{3, 0}, {-3, 0}, {9, 0}, {-9, 0}, {0, 2}, {0, -2}, {5, 3}, {-5, -3}} {
+			sr := image.Rect(7, 3, 27, 7)
+			got := randomMono(image.Rect(0, 0, 40, 10), 3)
+			orig := append(Mono(nil), got...)
+			want := append(Mono(nil), got...)
+			Blit(got, sr.Min.Add(d), got, sr, op)
+			blitPixels(want, sr.Min.Add(d), orig, sr, op)
+			if !bytes.Equal(got, want) {
+				t.Errorf("op %d shift by %v: got %X, want %X", op, d, []byte(got), []byte(want))
+			}
+		}
+	}
+}
+
+func TestBlitView(t *testing.T) {
+	dst := NewMono(image.Rect(0, 0, 16, 4))
+	sprite := NewMonoView(image.Rect(100, 100, 104, 102))
+	sprite.Clear(color.White)
+	sprite.SetBit(100, 100, false)
+
+	// drawn through view with local coordinates and clipped by it
+	view := dst.SubImage(image.Rect(4, 1, 16, 4)).(*MonoView).Origin(image.ZP)
+	Blit(view, image.Pt(-1, 1), sprite, sprite.Bounds(), RasterTransparentBlack)
+	if want := []byte{0, 16, 0, 4, 0, 0, 0, 0, 0x0E, 0, 0x0E, 0}; !bytes.Equal(dst, want) {
+		t.Errorf("got %X, want %X", []byte(dst), want)
+	}
+
+	// other images are converted
+	gray := image.NewGray(image.Rect(0, 0, 2, 1))
+	gray.SetGray(1, 0, color.Gray{0xFF})
+	Blit(&dst, image.Pt(0, 0), gray, gray.Bounds(), RasterXor)
+	if dst.Bit(0, 0) || !dst.Bit(1, 0) {
+		t.Errorf("gray source blitted wrong")
+	}
+}
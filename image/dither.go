@@ -0,0 +1,136 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Luminance holds weights of red, green and blue channels used to compute brightness
+type Luminance struct {
+	R, G, B float64
+}
+
+var (
+	Average = Luminance{1.0 / 3, 1.0 / 3, 1.0 / 3} // same as default color model of Mono
+	Rec601  = Luminance{0.299, 0.587, 0.114}
+	Rec709  = Luminance{0.2126, 0.7152, 0.0722}
+)
+
+// Gray returns brightness of color in range 0 (black) to 1 (white),
+// transparent colors are composed over white
+func (l Luminance) Gray(c color.Color) float64 {
+	if l == (Luminance{}) {
+		l = Average
+	}
+	r, g, b, a := c.RGBA()
+	y := (l.R*float64(r) + l.G*float64(g) + l.B*float64(b)) / (l.R + l.G + l.B)
+	return (y + float64(0xFFFF-a)) / 0xFFFF
+}
+
+// Diffusion is error diffusion kernel,
+// error of each pixel is distributed to its neighbours by given weights divided by Divisor
+type Diffusion struct {
+	Divisor int
+	Weights []Weight
+}
+
+// Weight is share of the error for neighbour at DX, DY from the current pixel
+type Weight struct {
+	DX, DY, W int
+}
+
+var (
+	FloydSteinberg = Diffusion{16, []Weight{
+		{1, 0, 7},
+		{-1, 1, 3}, {0, 1, 5}, {1, 1, 1},
+	}}
+	Atkinson = Diffusion{8, []Weight{ // only 3/4 of the error is diffused
+		{1, 0, 1}, {2, 0, 1},
+		{-1, 1, 1}, {0, 1, 1}, {1, 1, 1},
+		{0, 2, 1},
+	}}
+	Stucki = Diffusion{42, []Weight{
+		{1, 0, 8}, {2, 0, 4},
+		{-2, 1, 2}, {-1, 1, 4}, {0, 1, 8}, {1, 1, 4}, {2, 1, 2},
+		{-2, 2, 1}, {-1, 2, 2}, {0, 2, 4}, {1, 2, 2}, {2, 2, 1},
+	}}
+	Sierra = Diffusion{32, []Weight{
+		{1, 0, 5}, {2, 0, 3},
+		{-2, 1, 2}, {-1, 1, 4}, {0, 1, 5}, {1, 1, 4}, {2, 1, 2},
+		{-1, 2, 2}, {0, 2, 3}, {1, 2, 2},
+	}}
+)
+
+// ErrorDiffusion converts images to black and white by error diffusion dithering.
+//
+// It implements image/draw.Drawer, so it can be used to paste photos into Mono:
+//
+//	ErrorDiffusion{Diffusion: Atkinson}.Draw(m, m.Bounds(), photo, image.ZP)
+type ErrorDiffusion struct {
+	Diffusion  Diffusion // FloydSteinberg if not set
+	Serpentine bool      // process odd rows from right to left
+	Gamma      float64   // brightness is raised to the power of Gamma, 0 means 1
+	Luminance  Luminance // Average if not set
+}
+
+// Draw implements image/draw.Drawer interface
+func (e ErrorDiffusion) Draw(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) {
+	r, sp = clip(dst, r, src, sp)
+	if r.Empty() {
+		return
+	}
+	diffusion := e.Diffusion
+	if diffusion.Divisor == 0 {
+		diffusion = FloydSteinberg
+	}
+	gamma := e.Gamma
+	if gamma == 0 {
+		gamma = 1
+	}
+
+	w, h := r.Dx(), r.Dy()
+	gray := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := e.Luminance.Gray(src.At(sp.X+x, sp.Y+y))
+			if gamma != 1 {
+				v = math.Pow(v, gamma)
+			}
+			gray[y*w+x] = v
+		}
+	}
+
+	for y := 0; y < h; y++ {
+		x, step := 0, 1
+		if e.Serpentine && y%2 == 1 {
+			x, step = w-1, -1
+		}
+		for ; x >= 0 && x < w; x += step {
+			old := gray[y*w+x]
+			c, v := color.Color(color.Black), 0.0
+			if old >= 0.5 {
+				c, v = color.White, 1.0
+			}
+			dst.Set(r.Min.X+x, r.Min.Y+y, c)
+
+			err := (old - v) / float64(diffusion.Divisor)
+			for _, k := range diffusion.Weights {
+				nx, ny := x+k.DX*step, y+k.DY
+				if nx >= 0 && nx < w && ny < h {
+					gray[ny*w+nx] += err * float64(k.W)
+				}
+			}
+		}
+	}
+}
+
+// clip limits rectangle r to bounds of both dst and src like image/draw does,
+// sp is moved accordingly
+func clip(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) (image.Rectangle, image.Point) {
+	orig := r.Min
+	r = r.Intersect(dst.Bounds())
+	r = r.Intersect(src.Bounds().Add(orig.Sub(sp)))
+	return r, sp.Add(r.Min.Sub(orig))
+}
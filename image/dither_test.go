@@ -0,0 +1,81 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func countWhite(m Mono) (n int) {
+	for y := 0; y < int(m.Height()); y++ {
+		for x := 0; x < int(m.Width()); x++ {
+			if m.At(x, y) == color.White {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestErrorDiffusion(t *testing.T) {
+	rect := image.Rect(0, 0, 64, 64)
+	for name, d := range map[string]Diffusion{
+		"floyd-steinberg": FloydSteinberg,
+		"atkinson":        Atkinson,
+		"stucki":          Stucki,
+		"sierra":          Sierra,
+	} {
+		for _, serpentine := range []bool{false, true} {
+			e := ErrorDiffusion{Diffusion: d, Serpentine: serpentine}
+			for _, tc := range []struct {
+				gray     uint8
+				min, max int
+			}{
+				{0x00, 0, 0},
+				{0xFF, 64 * 64, 64 * 64},
+				{0x80, 64 * 64 * 40 / 100, 64 * 64 * 60 / 100},
+				{0x40, 64 * 64 * 15 / 100, 64 * 64 * 35 / 100},
+			} {
+				m := NewMono(rect)
+				e.Draw(&m, rect, image.NewUniform(color.Gray{tc.gray}), image.ZP)
+				if n := countWhite(m); n < tc.min || n > tc.max {
+					t.Errorf("%s (serpentine %v): %d white pixels for gray %X", name, serpentine, n, tc.gray)
+				}
+			}
+		}
+	}
+}
+
+func TestErrorDiffusionClip(t *testing.T) {
+	m := NewMono(image.Rect(0, 0, 16, 16))
+	m.Clear(color.Black)
+	src := image.NewGray(image.Rect(0, 0, 4, 4))
+	for i := range src.Pix {
+		src.Pix[i] = 0xFF
+	}
+	ErrorDiffusion{}.Draw(&m, image.Rect(10, 10, 20, 20), src, image.Pt(1, 1))
+	if n := countWhite(m); n != 9 {
+		t.Errorf("%d white pixels, expected 9", n)
+	}
+	if m.At(10, 10) != color.White || m.At(13, 13) == color.White {
+		t.Error("image drawn to wrong place")
+	}
+}
+
+func TestGammaAndLuminance(t *testing.T) {
+	rect := image.Rect(0, 0, 32, 32)
+	green := image.NewUniform(color.RGBA{0, 0xFF, 0, 0xFF})
+
+	avg, rec601 := NewMono(rect), NewMono(rect)
+	ErrorDiffusion{}.Draw(&avg, rect, green, image.ZP)
+	ErrorDiffusion{Luminance: Rec601}.Draw(&rec601, rect, green, image.ZP)
+	if countWhite(rec601) <= countWhite(avg) {
+		t.Error("green should be brighter with Rec601 weights")
+	}
+
+	dark := NewMono(rect)
+	ErrorDiffusion{Luminance: Rec601, Gamma: 2.2}.Draw(&dark, rect, green, image.ZP)
+	if countWhite(dark) >= countWhite(rec601) {
+		t.Error("gamma above 1 should darken")
+	}
+}
@@ -0,0 +1,138 @@
+package image
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"io"
+)
+
+// Mono file starts with magic bytes and version followed by Mono itself:
+//
+//	"MONO"         magic
+//	0x01           version
+//	width, height  big-endian uint16 each
+//	bitmap         rows padded to whole bytes, most significant bit first, 1 is white
+const (
+	magic   = "MONO"
+	version = 1
+)
+
+var (
+	ErrFormat  = errors.New("mono: not a Mono image")
+	ErrVersion = errors.New("mono: unsupported version")
+	ErrLength  = errors.New("mono: data length does not match dimensions")
+	ErrSize    = errors.New("mono: image too large")
+)
+
+func init() {
+	image.RegisterFormat("mono", magic, Decode, DecodeConfig)
+}
+
+// Parse validates raw Mono data (header without magic bytes followed by bitmap)
+func Parse(data []byte) (Mono, error) {
+	if len(data) < 4 {
+		return nil, ErrLength
+	}
+	m := Mono(data)
+	if len(data) != 4+monoLength(m.Width(), m.Height()) {
+		return nil, ErrLength
+	}
+	return m, nil
+}
+
+func monoLength(width, height uint) int {
+	return int((width+7)/8) * int(height)
+}
+
+func readHeader(r io.Reader) (header [9]byte, err error) {
+	if _, err = io.ReadFull(r, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF || err == io.EOF {
+			err = ErrFormat
+		}
+		return
+	}
+	if string(header[:4]) != magic {
+		return header, ErrFormat
+	}
+	if header[4] != version {
+		return header, ErrVersion
+	}
+	return header, nil
+}
+
+// DecodeConfig returns dimensions of Mono image without reading the bitmap
+func DecodeConfig(r io.Reader) (image.Config, error) {
+	header, err := readHeader(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	m := Mono(header[5:])
+	return image.Config{
+		ColorModel: colorModel(),
+		Width:      int(m.Width()),
+		Height:     int(m.Height()),
+	}, nil
+}
+
+// Decode reads Mono image, the returned image.Image is Mono
+func Decode(r io.Reader) (image.Image, error) {
+	header, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	m := Mono(header[5:])
+	data, err := readData(r, header[5:], monoLength(m.Width(), m.Height()))
+	if err != nil {
+		return nil, err
+	}
+	return Mono(data), nil
+}
+
+// readData reads n bytes and returns them following prefix.
+// The buffer grows only as the data arrives, so dimensions in the header
+// of truncated or forged file do not allocate the whole bitmap.
+func readData(r io.Reader, prefix []byte, n int) ([]byte, error) {
+	buf := bytes.NewBuffer(append([]byte(nil), prefix...))
+	if _, err := io.CopyN(buf, r, int64(n)); err != nil {
+		if err == io.EOF {
+			err = ErrLength
+		}
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Encode writes image in Mono format,
+// images other than Mono are converted by its color model first
+func Encode(w io.Writer, img image.Image) error {
+	m, err := toMono(img)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString(magic)
+	bw.WriteByte(version)
+	bw.Write(m)
+	return bw.Flush()
+}
+
+// toMono returns valid Mono for given image, converting it if needed
+func toMono(img image.Image) (Mono, error) {
+	m, ok := img.(Mono)
+	if p, isPtr := img.(*Mono); isPtr {
+		m, ok = *p, true
+	}
+	if ok {
+		return Parse(m)
+	}
+	b := img.Bounds()
+	if b.Dx() > 0xFFFF || b.Dy() > 0xFFFF {
+		return nil, ErrSize
+	}
+	m = NewMono(b)
+	draw.Draw(&m, m.Bounds(), img, b.Min, draw.Src)
+	return m, nil
+}
@@ -0,0 +1,106 @@
+package image
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"runtime"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	m := NewMono(image.Rect(0, 0, 13, 5))
+	m.Clear(color.White)
+	m.Set(12, 4, color.Black)
+	m.Set(3, 1, color.Black)
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	if want := 5 + 4 + 2*5; buf.Len() != want {
+		t.Errorf("encoded %d bytes, want %d", buf.Len(), want)
+	}
+
+	config, name, err := image.DecodeConfig(bytes.NewReader(buf.Bytes()))
+	if err != nil || name != "mono" || config.Width != 13 || config.Height != 5 {
+		t.Errorf("DecodeConfig: %v %q %v", config, name, err)
+	}
+	img, name, err := image.Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil || name != "mono" {
+		t.Fatalf("Decode: %q %v", name, err)
+	}
+	if !bytes.Equal(img.(Mono), m) {
+		t.Errorf("decoded %X, want %X", img.(Mono), m)
+	}
+}
+
+func TestEncodeConverts(t *testing.T) {
+	img := image.NewGray(image.Rect(2, 3, 10, 4))
+	img.SetGray(2, 3, color.Gray{0xFF})
+	var buf bytes.Buffer
+	if err := Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte("MONO\x01\x00\x08\x00\x01\x80")
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("encoded %X, want %X", buf.Bytes(), want)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		data string
+		err  error
+	}{
+		{"", ErrFormat},
+		{"MONO\x01\x00", ErrFormat},
+		{"MOON\x01\x00\x08\x00\x01\x80", ErrFormat},
+		{"MONO\x02\x00\x08\x00\x01\x80", ErrVersion},
+		{"MONO\x01\x00\x09\x00\x02\x80\x00\x80", ErrLength},
+	} {
+		if _, err := Decode(bytes.NewReader([]byte(tc.data))); err != tc.err {
+			t.Errorf("%q: got %v, want %v", tc.data, err, tc.err)
+		}
+	}
+}
+
+// allocated returns number of bytes allocated by f
+func allocated(f func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	f()
+	runtime.ReadMemStats(&after)
+	return after.TotalAlloc - before.TotalAlloc
+}
+
+func TestDecodeForgedSize(t *testing.T) {
+	n := allocated(func() {
+		if _, err := Decode(bytes.NewReader([]byte("MONO\x01\xff\xff\xff\xff\x00"))); err != ErrLength {
+			t.Errorf("got %v, want ErrLength", err)
+		}
+	})
+	if n > 1<<20 {
+		t.Errorf("%d bytes allocated for truncated data", n)
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		data string
+		err  error
+	}{
+		{"\x00\x08\x00\x01\x80", nil},
+		{"\x00\x09\x00\x02\x80\x00\x80\x00", nil},
+		{"\x00\x09\x00\x02\x80\x00\x80", ErrLength},
+		{"\x00\x08\x00\x01\x80\x00", ErrLength},
+		{"\x00\x08", ErrLength},
+	} {
+		if _, err := Parse([]byte(tc.data)); err != tc.err {
+			t.Errorf("%q: got %v, want %v", tc.data, err, tc.err)
+		}
+	}
+	if err := Encode(new(bytes.Buffer), Mono("\x00\x08\x00\x02\x80")); err != ErrLength {
+		t.Errorf("Encode of invalid Mono: %v", err)
+	}
+}
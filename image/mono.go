@@ -9,16 +9,28 @@ import (
 	"image/color"
 	"math"
 	"math/bits"
+	"sync/atomic"
 )
 
 var (
-	goFont     *truetype.Font
-	colorModel color.Model
+	goFont            *truetype.Font
+	currentModel      atomic.Value // modelHolder, replaced by SetColorModel while images are drawn
+	defaultColorModel color.Model
 )
 
+// modelHolder gives atomic.Value the same concrete type for any color model
+type modelHolder struct {
+	color.Model
+}
+
+// colorModel returns model used by Mono to convert colors to black and white
+func colorModel() color.Model {
+	return currentModel.Load().(modelHolder).Model
+}
+
 func init() {
 	goFont, _ = truetype.Parse(gobold.TTF)
-	colorModel = color.ModelFunc(func(c color.Color) color.Color {
+	defaultColorModel = color.ModelFunc(func(c color.Color) color.Color {
 		r, g, b, _ := c.RGBA()
 		avg := (r + g + b) / 3
 		if avg < 1<<15 { // tresholding
@@ -27,13 +39,23 @@ func init() {
 			return color.White
 		}
 	})
+	currentModel.Store(modelHolder{defaultColorModel})
 }
 
 // Mono is monochromatic image
 //
+// It starts with big-endian uint16 width and height followed by bitmap,
+// rows are padded to whole bytes and bit 1 is white.
+// Use Encode and Decode to store it in files.
+//
 // It implements image.Image and image/draw.Image interface
 type Mono []byte
 
+// NewMono creates white-black image of size of rect.
+//
+// Mono has no origin, its header holds only the size, so its bounds always start at 0,0
+// and rect.Min is ignored. Images with arbitrary origin, like image.Gray has,
+// are MonoView, created by NewMonoView or by SubImage of Mono.
 func NewMono(rect image.Rectangle) Mono {
 	width, height := rect.Size().X, rect.Size().Y
 	h := height
@@ -67,20 +89,7 @@ func (m Mono) Height() uint {
 //
 // Implements image/draw.Image interface.
 func (m Mono) Set(x, y int, c color.Color) {
-	if x < 0 || y < 0 || x >= int(m.Width()) || y >= int(m.Height()) {
-		return
-	}
-	w := m.Width()
-	if w%8 > 0 {
-		w += 8 - w%8
-	}
-	i := uint(x) + uint(y)*w
-	Y := colorModel.Convert(c).(color.Gray16).Y
-	if Y == 0 {
-		m[4+i/8] &^= (1 << (7 - i%8)) // clr
-	} else {
-		m[4+i/8] |= (1 << (7 - i%8)) // set
-	}
+	m.SetBit(x, y, isWhite(c))
 }
 
 // At returns color at giver coordinates.
@@ -88,19 +97,52 @@ func (m Mono) Set(x, y int, c color.Color) {
 //
 // Implements image.Image iterface.
 func (m Mono) At(x, y int) color.Color {
-	w := m.Width()
-	if w%8 > 0 {
-		w += 8 - w%8
+	if m.Bit(x, y) {
+		return color.White
 	}
-	i := uint(x) + uint(y)*w
-	bit := m[4+i/8] & (1 << (7 - i%8))
-	if bit == 0 {
-		return color.Black
+	return color.Black
+}
+
+// SetBit sets pixel on given coordinates to white (true) or black (false)
+// without any color conversion
+func (m Mono) SetBit(x, y int, white bool) {
+	if x < 0 || y < 0 || x >= int(m.Width()) || y >= int(m.Height()) {
+		return
+	}
+	i := 4 + y*m.stride() + x/8
+	if white {
+		m[i] |= 0x80 >> uint(x%8) // set
 	} else {
-		return color.White
+		m[i] &^= 0x80 >> uint(x%8) // clr
 	}
 }
 
+// Bit returns true if pixel on given coordinates is white,
+// pixels outside of the image are black
+func (m Mono) Bit(x, y int) bool {
+	if x < 0 || y < 0 || x >= int(m.Width()) || y >= int(m.Height()) {
+		return false
+	}
+	return m[4+y*m.stride()+x/8]&(0x80>>uint(x%8)) != 0
+}
+
+// stride returns number of bytes per row
+func (m Mono) stride() int {
+	return int(m.Width()+7) / 8
+}
+
+// isWhite converts color to white (true) or black (false) by current color model
+func isWhite(c color.Color) bool {
+	switch c {
+	case color.White:
+		return true
+	case color.Black:
+		return false
+	}
+	Y, _, _, _ := colorModel().Convert(c).RGBA()
+	return Y >= 1<<15
+}
+
 // Bounds returns Rectangle bounding the image.
 //
 // Implements image.Image interface.
@@ -110,11 +152,11 @@ func (m Mono) Bounds() image.Rectangle {
 
 // ColorModel return color.Model of the image.
 // Color converted to this model results either to color.Black or color.White.
-// Basic fixed tresholding method is used.
+// Basic fixed tresholding method is used unless changed by SetColorModel.
 //
 // Implement image.Image interface.
 func (m Mono) ColorModel() color.Model {
-	return colorModel
+	return colorModel()
 }
 
 // Clear sets whole bitmap to given color - color.Black or color.White
@@ -130,26 +172,28 @@ func (m *Mono) Clear(c color.Color) {
 //
 // lengtj is distance between centers of first and last dot:
 // line of len 0 is dot -> line will consists of length+1 dots
-func (m *Mono) DrawHorizontalLine(color color.Color, start image.Point, length int) {
-	for x := start.X; x <= start.X+length; x++ {
-		m.Set(x, start.Y, color)
+func (m *MonoView) DrawHorizontalLine(color color.Color, start image.Point, length int) {
+	if length < 0 {
+		return
 	}
+	m.fill(image.Rect(start.X, start.Y, start.X+length+1, start.Y+1), color)
 }
 
 // DrawHorizontalLine draws vettical line given by top most point and length
 //
 // length is distance between centers of first and last dot:
 // line of len 0 is dot -> line will consists of length+1 dots
-func (m *Mono) DrawVerticalLine(color color.Color, start image.Point, length int) {
-	for y := start.Y; y <= start.Y+length; y++ {
-		m.Set(start.X, y, color)
+func (m *MonoView) DrawVerticalLine(color color.Color, start image.Point, length int) {
+	if length < 0 {
+		return
 	}
+	m.fill(image.Rect(start.X, start.Y, start.X+1, start.Y+length+1), color)
 }
 
 // Draw arbitrary line
 //
 // line with same start and end is 1 dot
-func (m *Mono) DrawLine(color color.Color, start image.Point, end image.Point) {
+func (m *MonoView) DrawLine(color color.Color, start image.Point, end image.Point) {
 	drawLineLow := func(start, end image.Point) {
 		delta := end.Sub(start)
 		yi := +1
@@ -203,7 +247,7 @@ func (m *Mono) DrawLine(color color.Color, start image.Point, end image.Point) {
 }
 
 // StrokeRect draws outline of rectangle
-func (m *Mono) StrokeRect(color color.Color, rect image.Rectangle) {
+func (m *MonoView) StrokeRect(color color.Color, rect image.Rectangle) {
 	w, h := rect.Dx(), rect.Dy()
 	m.DrawHorizontalLine(color, rect.Min, w)
 	m.DrawHorizontalLine(color, rect.Min.Add(image.Pt(0, h)), w)
@@ -212,18 +256,17 @@ func (m *Mono) StrokeRect(color color.Color, rect image.Rectangle) {
 }
 
 // StrokeRect draws filled rectangle
-func (m *Mono) FillRect(color color.Color, rect image.Rectangle) {
-	w := rect.Dx()
-	down := image.Pt(0, 1)
-	for start := rect.Min; start.Y <= rect.Max.Y; start = start.Add(down) {
-		m.DrawHorizontalLine(color, start, w)
+func (m *MonoView) FillRect(color color.Color, rect image.Rectangle) {
+	if rect.Dx() < 0 || rect.Dy() < 0 {
+		return
 	}
+	m.fill(image.Rectangle{rect.Min, rect.Max.Add(image.Pt(1, 1))}, color) // including Max
 }
 
 // StrokeCircle draws outline of circle given by center point and raidus.
 //
 // Center is the coords of pixel in center - circle with radius 3 will be 5 px wide.
-func (m *Mono) StrokeCircle(color color.Color, center image.Point, radius int) {
+func (m *MonoView) StrokeCircle(color color.Color, center image.Point, radius int) {
 	x := radius - 1
 	y := 0
 	dx := 1
@@ -256,7 +299,7 @@ func (m *Mono) StrokeCircle(color color.Color, center image.Point, radius int) {
 // FillCircle draws filled circle given by center point and radius.
 //
 // Center is the coords of pixel in center - circle with radius 3 will be 5 px wide.
-func (m *Mono) FillCircle(color color.Color, center image.Point, radius int) {
+func (m *MonoView) FillCircle(color color.Color, center image.Point, radius int) {
 	for x := 0; x < radius; x++ {
 		for y := 0; y < radius; y++ {
 			if x*x+y*y <= radius*radius {
@@ -269,7 +312,7 @@ func (m *Mono) FillCircle(color color.Color, center image.Point, radius int) {
 	}
 }
 
-func (m *Mono) DrawString(color color.Color, text string, size float64, dot image.Point) {
+func (m *MonoView) DrawString(color color.Color, text string, size float64, dot image.Point) {
 	d := font.Drawer{
 		Dst: m,
 		Src: image.NewUniform(color),
@@ -320,14 +363,26 @@ func (m *Mono) HorizontalFlip() {
 //
 // (center of rotation is center of largest square fitted to top left)
 func (m *Mono) RotateRight() {
-	w := m.Width()
-	h := m.Height()
-
-	n := NewMono(image.Rect(0, 0, int(h), int(w)))
-
-	for y := 0; y < int(h); y++ {
-		for x := 0; x < int(w); x++ {
-			n.Set(int(h)-1-y, x, m.At(x, y))
+	w, h := int(m.Width()), int(m.Height())
+	n := NewMono(image.Rect(0, 0, h, w))
+	src, dst := m.Bitmap(), n.Bitmap()
+	srcStride, dstStride := m.stride(), n.stride()
+
+	// pixel x, y goes to h-1-y, x
+	// so byte j of row x is made of column x of rows h-1-8j down to h-8-8j
+	for j := 0; j < dstStride; j++ {
+		for bx := 0; bx < srcStride; bx++ {
+			var block uint64
+			for i := 0; i < 8; i++ {
+				block <<= 8
+				if y := h - 1 - 8*j - i; y >= 0 {
+					block |= uint64(src[y*srcStride+bx])
+				}
+			}
+			block = transpose8(block)
+			for k := 0; k < 8 && 8*bx+k < w; k++ {
+				dst[(8*bx+k)*dstStride+j] = byte(block >> uint(56-8*k))
+			}
 		}
 	}
 	*m = n
@@ -337,19 +392,74 @@ func (m *Mono) RotateRight() {
 //
 // (center of rotation is center of largest square fitted to top left)
 func (m *Mono) RotateLeft() {
-	w := m.Width()
-	h := m.Height()
-
-	n := NewMono(image.Rect(0, 0, int(h), int(w)))
-
-	for y := 0; y < int(h); y++ {
-		for x := 0; x < int(w); x++ {
-			n.Set(y, int(w)-1-x, m.At(x, y))
+	w, h := int(m.Width()), int(m.Height())
+	n := NewMono(image.Rect(0, 0, h, w))
+	src, dst := m.Bitmap(), n.Bitmap()
+	srcStride, dstStride := m.stride(), n.stride()
+
+	// pixel x, y goes to y, w-1-x
+	// so byte j of row w-1-x is made of column x of rows 8j to 8j+7
+	for j := 0; j < dstStride; j++ {
+		for bx := 0; bx < srcStride; bx++ {
+			var block uint64
+			for i := 0; i < 8; i++ {
+				block <<= 8
+				if y := 8*j + i; y < h {
+					block |= uint64(src[y*srcStride+bx])
+				}
+			}
+			block = transpose8(block)
+			for k := 0; k < 8 && 8*bx+k < w; k++ {
+				dst[(w-1-8*bx-k)*dstStride+j] = byte(block >> uint(56-8*k))
+			}
 		}
 	}
 	*m = n
 }
 
+// Rotate180 will rotate image 180 degrees in place
+func (m *Mono) Rotate180() {
+	w, h := int(m.Width()), int(m.Height())
+	data := m.Bitmap()
+	stride := m.stride()
+	pad := uint(stride*8 - w)
+	tmp := make([]byte, stride)
+	for y := 0; y < (h+1)/2; y++ {
+		top, bottom := data[y*stride:][:stride], data[(h-1-y)*stride:][:stride]
+		copy(tmp, top)
+		reverseRow(top, bottom, pad)
+		reverseRow(bottom, tmp, pad) // the middle row is overwritten by itself
+	}
+}
+
+// reverseRow writes pixels of src into dst in reversed order,
+// both rows are padded by pad bits
+func reverseRow(dst, src []byte, pad uint) {
+	n := len(src)
+	for i := range dst {
+		dst[i] = flipByte(src[n-1-i])
+	}
+	if pad == 0 {
+		return
+	}
+	for i := 0; i < n-1; i++ {
+		dst[i] = dst[i]<<pad | dst[i+1]>>(8-pad)
+	}
+	dst[n-1] <<= pad
+}
+
+// transpose8 transposes 8x8 bit matrix,
+// rows are bytes from the most significant one, columns are bits from the most significant one
+func transpose8(x uint64) uint64 {
+	t := (x ^ (x >> 7)) & 0x00AA00AA00AA00AA
+	x ^= t ^ (t << 7)
+	t = (x ^ (x >> 14)) & 0x0000CCCC0000CCCC
+	x ^= t ^ (t << 14)
+	t = (x ^ (x >> 28)) & 0x00000000F0F0F0F0
+	x ^= t ^ (t << 28)
+	return x
+}
+
 // Invert inverts colors in image
 func (m *Mono) Invert() {
 	for i := 4; i < len(*m); i++ {
@@ -105,3 +105,104 @@ func BenchmarkFillCircleB(b *testing.B) {
 		fillCircleB(&m, color.Black, image.Pt(512, 512), 421)
 	}
 }
+
+func BenchmarkSet(b *testing.B) {
+	m := NewMono(image.Rect(0, 0, 296, 128))
+	for n := 0; n < b.N; n++ {
+		for y := 0; y < 128; y++ {
+			for x := 0; x < 296; x++ {
+				m.Set(x, y, color.Gray{uint8(x + y)})
+			}
+		}
+	}
+}
+func BenchmarkSetBit(b *testing.B) {
+	m := NewMono(image.Rect(0, 0, 296, 128))
+	for n := 0; n < b.N; n++ {
+		for y := 0; y < 128; y++ {
+			for x := 0; x < 296; x++ {
+				m.SetBit(x, y, (x+y)&0x80 != 0)
+			}
+		}
+	}
+}
+func BenchmarkFillRectC(b *testing.B) {
+	m := NewMono(image.Rect(0, 0, 1024, 1024))
+	for n := 0; n < b.N; n++ {
+		Drawer.Draw(&m, image.Rect(19, 23, 1001, 1017), image.White, image.ZP)
+		Drawer.Draw(&m, image.Rect(19, 23, 1001, 1017), image.Black, image.ZP)
+	}
+}
+func BenchmarkCopyA(b *testing.B) {
+	src := NewMono(image.Rect(0, 0, 296, 128))
+	m := NewMono(image.Rect(0, 0, 296, 128))
+	for n := 0; n < b.N; n++ {
+		draw.Draw(&m, image.Rect(3, 5, 291, 123), src, image.ZP, draw.Src)
+	}
+}
+func BenchmarkCopyB(b *testing.B) {
+	src := NewMono(image.Rect(0, 0, 296, 128))
+	m := NewMono(image.Rect(0, 0, 296, 128))
+	for n := 0; n < b.N; n++ {
+		Drawer.Draw(&m, image.Rect(3, 5, 291, 123), src, image.ZP)
+	}
+}
+func BenchmarkBlit(b *testing.B) {
+	src := NewMono(image.Rect(0, 0, 64, 64))
+	m := NewMono(image.Rect(0, 0, 296, 128))
+	for n := 0; n < b.N; n++ {
+		Blit(&m, image.Pt(13, 7), src, src.Bounds(), RasterXor)
+	}
+}
+
+func rotateRightA(m *Mono) {
+	w, h := int(m.Width()), int(m.Height())
+	n := NewMono(image.Rect(0, 0, h, w))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			n.Set(h-1-y, x, m.At(x, y))
+		}
+	}
+	*m = n
+}
+func rotate180A(m *Mono) {
+	w, h := int(m.Width()), int(m.Height())
+	n := NewMono(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			n.Set(w-1-x, h-1-y, m.At(x, y))
+		}
+	}
+	*m = n
+}
+
+func BenchmarkRotateRightA(b *testing.B) {
+	m := NewMono(image.Rect(0, 0, 296, 128))
+	for n := 0; n < b.N; n++ {
+		rotateRightA(&m)
+	}
+}
+func BenchmarkRotateRightB(b *testing.B) {
+	m := NewMono(image.Rect(0, 0, 296, 128))
+	for n := 0; n < b.N; n++ {
+		m.RotateRight()
+	}
+}
+func BenchmarkRotateLeftB(b *testing.B) {
+	m := NewMono(image.Rect(0, 0, 296, 128))
+	for n := 0; n < b.N; n++ {
+		m.RotateLeft()
+	}
+}
+func BenchmarkRotate180A(b *testing.B) {
+	m := NewMono(image.Rect(0, 0, 296, 128))
+	for n := 0; n < b.N; n++ {
+		rotate180A(&m)
+	}
+}
+func BenchmarkRotate180B(b *testing.B) {
+	m := NewMono(image.Rect(0, 0, 296, 128))
+	for n := 0; n < b.N; n++ {
+		m.Rotate180()
+	}
+}
@@ -20,7 +20,9 @@ var (
 
 func TestMono(t *testing.T) {
 
-	epaper.Setup()
+	if err := epaper.Setup(); err != nil {
+		t.Fatal(err)
+	}
 	defer epaper.Teardown()
 	epaper.Init("full")
 	defer epaper.Sleep()
@@ -82,7 +84,9 @@ func TestMono(t *testing.T) {
 }
 
 func TestLines(t *testing.T) {
-	epaper.Setup()
+	if err := epaper.Setup(); err != nil {
+		t.Fatal(err)
+	}
 	defer epaper.Teardown()
 	epaper.Init("full")
 	defer epaper.Sleep()
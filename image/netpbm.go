@@ -0,0 +1,304 @@
+package image
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"strconv"
+)
+
+// ErrNetpbm is returned for malformed PBM and PGM headers and rasters
+var ErrNetpbm = errors.New("netpbm: invalid format")
+
+func init() {
+	image.RegisterFormat("pbm", "P1", decodePBM, decodeNetpbmConfig)
+	image.RegisterFormat("pbm", "P4", decodePBM, decodeNetpbmConfig)
+	image.RegisterFormat("pgm", "P2", DecodePGM, decodeNetpbmConfig)
+	image.RegisterFormat("pgm", "P5", DecodePGM, decodeNetpbmConfig)
+}
+
+type netpbmHeader struct {
+	magic         string
+	width, height int
+	maxval        int // 1 for PBM
+}
+
+// readToken returns next whitespace separated token skipping comments,
+// whitespace following the token is consumed
+func readToken(r *bufio.Reader) (string, error) {
+	var token []byte
+	for {
+		c, err := r.ReadByte()
+		if err == io.EOF && len(token) > 0 {
+			return string(token), nil
+		}
+		if err != nil {
+			return "", ErrNetpbm
+		}
+		switch {
+		case c == '#' && len(token) == 0:
+			if _, err := r.ReadBytes('\n'); err != nil {
+				return "", ErrNetpbm
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f':
+			if len(token) > 0 {
+				return string(token), nil
+			}
+		default:
+			token = append(token, c)
+		}
+	}
+}
+
+func readNumber(r *bufio.Reader) (int, error) {
+	token, err := readToken(r)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(token)
+	if err != nil || n < 0 {
+		return 0, ErrNetpbm
+	}
+	return n, nil
+}
+
+func readNetpbmHeader(r *bufio.Reader) (h netpbmHeader, err error) {
+	if h.magic, err = readToken(r); err != nil {
+		return
+	}
+	if h.magic != "P1" && h.magic != "P2" && h.magic != "P4" && h.magic != "P5" {
+		return h, ErrNetpbm
+	}
+	if h.width, err = readNumber(r); err != nil {
+		return
+	}
+	if h.height, err = readNumber(r); err != nil {
+		return
+	}
+	if h.width > 0xFFFF || h.height > 0xFFFF {
+		return h, ErrSize
+	}
+	h.maxval = 1
+	if h.magic == "P2" || h.magic == "P5" {
+		if h.maxval, err = readNumber(r); err != nil {
+			return
+		}
+		if h.maxval == 0 || h.maxval > 0xFFFF {
+			return h, ErrNetpbm
+		}
+	}
+	return h, nil
+}
+
+func decodeNetpbmConfig(r io.Reader) (image.Config, error) {
+	h, err := readNetpbmHeader(bufio.NewReader(r))
+	if err != nil {
+		return image.Config{}, err
+	}
+	model := colorModel()
+	if h.maxval > 0xFF {
+		model = color.Gray16Model
+	} else if h.maxval > 1 {
+		model = color.GrayModel
+	}
+	return image.Config{ColorModel: model, Width: h.width, Height: h.height}, nil
+}
+
+func decodePBM(r io.Reader) (image.Image, error) {
+	return DecodePBM(r)
+}
+
+// DecodePBM reads plain (P1) or raw (P4) PBM image.
+//
+// Raw rows are padded to whole bytes the same way as in Mono, so padding bits are kept.
+// Padding bits of plain images are left black.
+func DecodePBM(r io.Reader) (Mono, error) {
+	br := bufio.NewReader(r)
+	h, err := readNetpbmHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	if h.magic != "P1" && h.magic != "P4" {
+		return nil, ErrNetpbm
+	}
+	// bitmap grows as the data arrives, dimensions in the header may be forged
+	header := []byte{byte(h.width >> 8), byte(h.width), byte(h.height >> 8), byte(h.height)}
+	stride := (h.width + 7) / 8
+
+	if h.magic == "P4" {
+		data, err := readData(br, header, stride*h.height)
+		if err != nil {
+			return nil, ErrLength
+		}
+		for i := 4; i < len(data); i++ {
+			data[i] = ^data[i] // 1 is black in PBM
+		}
+		return Mono(data), nil
+	}
+
+	m := Mono(header)
+	row := make([]byte, stride)
+	for y := 0; y < h.height; y++ {
+		for i := range row {
+			row[i] = 0
+		}
+		for x := 0; x < h.width; {
+			c, err := br.ReadByte()
+			if err != nil {
+				return nil, ErrLength
+			}
+			switch c {
+			case '0':
+				row[x/8] |= 1 << (7 - uint(x%8))
+				x++
+			case '1':
+				x++
+			case '#':
+				br.ReadBytes('\n')
+			case ' ', '\t', '\n', '\r', '\v', '\f':
+			default:
+				return nil, ErrNetpbm
+			}
+		}
+		m = append(m, row...)
+	}
+	return m, nil
+}
+
+// DecodePGM reads plain (P2) or raw (P5) PGM image as *image.Gray,
+// or as *image.Gray16 if maximal value is above 255.
+// DecodePGMMono converts it into Mono by any drawer.
+func DecodePGM(r io.Reader) (image.Image, error) {
+	br := bufio.NewReader(r)
+	h, err := readNetpbmHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	if h.magic != "P2" && h.magic != "P5" {
+		return nil, ErrNetpbm
+	}
+	sample := func() (int, error) {
+		if h.magic == "P2" {
+			v, err := readNumber(br)
+			if err == ErrNetpbm {
+				err = ErrLength
+			}
+			return v, err
+		}
+		hi, err := br.ReadByte()
+		if err != nil || h.maxval <= 0xFF {
+			return int(hi), err
+		}
+		lo, err := br.ReadByte()
+		return int(hi)<<8 | int(lo), err
+	}
+
+	// samples are read before the image is allocated, dimensions in the header may be forged
+	var samples []uint16
+	for y := 0; y < h.height; y++ {
+		for x := 0; x < h.width; x++ {
+			v, err := sample()
+			if err != nil {
+				return nil, ErrLength
+			}
+			if v > h.maxval {
+				return nil, ErrNetpbm
+			}
+			samples = append(samples, uint16(v))
+		}
+	}
+
+	rect := image.Rect(0, 0, h.width, h.height)
+	maxval := uint32(h.maxval)
+	if h.maxval > 0xFF {
+		gray16 := image.NewGray16(rect)
+		for i, v := range samples {
+			// v*0xFFFF overflows int on 32-bit platforms
+			scaled := (uint32(v)*0xFFFF + maxval/2) / maxval
+			gray16.SetGray16(i%h.width, i/h.width, color.Gray16{uint16(scaled)})
+		}
+		return gray16, nil
+	}
+	gray := image.NewGray(rect)
+	for i, v := range samples {
+		gray.SetGray(i%h.width, i/h.width, color.Gray{uint8((uint32(v)*0xFF + maxval/2) / maxval)})
+	}
+	return gray, nil
+}
+
+// DecodePGMMono reads PGM image and converts it into Mono by given drawer,
+// e.g. Threshold, Otsu or ErrorDiffusion. Nil drawer means Threshold{}.
+func DecodePGMMono(r io.Reader, drawer draw.Drawer) (Mono, error) {
+	img, err := DecodePGM(r)
+	if err != nil {
+		return nil, err
+	}
+	if drawer == nil {
+		drawer = Threshold{}
+	}
+	m := NewMono(img.Bounds())
+	drawer.Draw(m, m.Bounds(), img, image.ZP)
+	return m, nil
+}
+
+// EncodePGM writes image as raw (P5) PGM with maximal value 255,
+// Mono is written as 0 for black and 255 for white
+func EncodePGM(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	if b.Dx() > 0xFFFF || b.Dy() > 0xFFFF {
+		return ErrSize
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "P5\n%d %d\n255\n", b.Dx(), b.Dy())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			bw.WriteByte(color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y)
+		}
+	}
+	return bw.Flush()
+}
+
+// EncodePBM writes image as raw (P4) PBM,
+// images other than Mono are converted by its color model first
+func EncodePBM(w io.Writer, img image.Image) error {
+	m, err := toMono(img)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "P4\n%d %d\n", m.Width(), m.Height())
+	for _, b := range m.Bitmap() {
+		bw.WriteByte(^b)
+	}
+	return bw.Flush()
+}
+
+// EncodePlainPBM writes image as plain (P1) PBM with one row per line,
+// long rows are wrapped at 70 characters
+func EncodePlainPBM(w io.Writer, img image.Image) error {
+	m, err := toMono(img)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "P1\n%d %d\n", m.Width(), m.Height())
+	width, height := int(m.Width()), int(m.Height())
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x > 0 && x%70 == 0 {
+				bw.WriteByte('\n')
+			}
+			if m.At(x, y) == color.White {
+				bw.WriteByte('0')
+			} else {
+				bw.WriteByte('1')
+			}
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,147 @@
+package image
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestPBMRoundTrip(t *testing.T) {
+	raw := []byte("P4\n10 2\n\x80\x3F\x55\x40")
+	m, err := DecodePBM(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0, 10, 0, 2, 0x7F, 0xC0, 0xAA, 0xBF}; !bytes.Equal(m, want) {
+		t.Errorf("decoded %X, want %X", []byte(m), want)
+	}
+	var buf bytes.Buffer
+	if err := EncodePBM(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), raw) {
+		t.Errorf("encoded %q, want %q", buf.Bytes(), raw)
+	}
+
+	plain := "P1\n10 2\n1000000000\n0101010101\n"
+	buf.Reset()
+	if err := EncodePlainPBM(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != plain {
+		t.Errorf("encoded %q, want %q", buf.String(), plain)
+	}
+}
+
+func TestDecodePlainPBM(t *testing.T) {
+	img, name, err := image.Decode(strings.NewReader("P1\n# comment\n3 2 # size\n1 0 1\n010"))
+	if err != nil || name != "pbm" {
+		t.Fatalf("%q %v", name, err)
+	}
+	m := img.(Mono)
+	if want := []byte{0, 3, 0, 2, 0x40, 0xA0}; !bytes.Equal(m, want) {
+		t.Errorf("decoded %X, want %X", []byte(m), want)
+	}
+}
+
+func TestDecodePGM(t *testing.T) {
+	for _, tc := range []struct {
+		data string
+		want []uint16
+	}{
+		{"P2\n3 1\n4\n0 2 4\n", []uint16{0, 0x8080, 0xFFFF}},
+		{"P5 3 1 255\n\x00\x80\xFF", []uint16{0, 0x8080, 0xFFFF}},
+		{"P5 2 1 1023\n\x00\x00\x03\xFF", []uint16{0, 0xFFFF}},
+		{"P2 1 1 40000\n32774\n", []uint16{0xD1C0}},
+	} {
+		img, name, err := image.Decode(strings.NewReader(tc.data))
+		if err != nil || name != "pgm" {
+			t.Errorf("%q: %q %v", tc.data, name, err)
+			continue
+		}
+		for x, want := range tc.want {
+			if got := color.Gray16Model.Convert(img.At(x, 0)).(color.Gray16).Y; got != want {
+				t.Errorf("%q: pixel %d is %X, want %X", tc.data, x, got, want)
+			}
+		}
+	}
+}
+
+func TestPGMMono(t *testing.T) {
+	m, err := DecodePGMMono(strings.NewReader("P2 10 1 4\n0 1 2 3 4 4 3 2 1 0\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0, 10, 0, 1, 0x3F, 0x00}; !bytes.Equal(m, want) {
+		t.Errorf("decoded %X, want %X", []byte(m), want)
+	}
+	m, err = DecodePGMMono(strings.NewReader("P2 10 1 4\n0 1 2 3 4 4 3 2 1 0\n"), Threshold{Level: 0.2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0, 10, 0, 1, 0x7F, 0x80}; !bytes.Equal(m, want) {
+		t.Errorf("threshold 0.2: decoded %X, want %X", []byte(m), want)
+	}
+
+	var buf bytes.Buffer
+	if err := EncodePGM(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	want := "P5\n10 1\n255\n\x00\xFF\xFF\xFF\xFF\xFF\xFF\xFF\xFF\x00"
+	if buf.String() != want {
+		t.Errorf("encoded %q, want %q", buf.String(), want)
+	}
+	back, err := DecodePGMMono(&buf, nil)
+	if err != nil || !bytes.Equal(back, m) {
+		t.Errorf("round trip %X %v, want %X", []byte(back), err, []byte(m))
+	}
+}
+
+func TestDecodeNetpbmInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		data string
+		err  error
+	}{
+		{"P3 1 1 255\n", ErrNetpbm},
+		{"P4 8", ErrNetpbm},
+		{"P4 9 2\n\x00\x00\x00", ErrLength},
+		{"P1 2 1\n12", ErrNetpbm},
+		{"P1 70000 1\n", ErrSize},
+	} {
+		if _, err := DecodePBM(strings.NewReader(tc.data)); err != tc.err {
+			t.Errorf("%q: got %v, want %v", tc.data, err, tc.err)
+		}
+	}
+	for _, tc := range []struct {
+		data string
+		err  error
+	}{
+		{"P5 2 1 255\n\x00", ErrLength},
+		{"P2 2 1 4\n0 5", ErrNetpbm},
+		{"P2 1 1 0\n0", ErrNetpbm},
+	} {
+		if _, err := DecodePGM(strings.NewReader(tc.data)); err != tc.err {
+			t.Errorf("%q: got %v, want %v", tc.data, err, tc.err)
+		}
+	}
+}
+
+func TestDecodeNetpbmForgedSize(t *testing.T) {
+	for _, data := range []string{
+		"P4 65535 65535\n\x00",
+		"P1 65535 65535\n0 1",
+		"P5 65535 65535 255\n\x00",
+		"P2 65535 65535 65535\n0 1",
+	} {
+		n := allocated(func() {
+			if _, _, err := image.Decode(strings.NewReader(data)); err != ErrLength {
+				t.Errorf("%q: got %v, want ErrLength", data, err)
+			}
+		})
+		if n > 1<<20 {
+			t.Errorf("%q: %d bytes allocated for truncated data", data, n)
+		}
+	}
+}
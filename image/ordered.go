@@ -0,0 +1,176 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"math/rand"
+)
+
+// ThresholdMatrix is square matrix of thresholds in range 0-1, repeated over the whole image
+type ThresholdMatrix struct {
+	Size       int
+	Thresholds []float64 // Size*Size values, row by row
+}
+
+// At returns threshold for pixel at given coordinates
+func (t ThresholdMatrix) At(x, y int) float64 {
+	x, y = x%t.Size, y%t.Size
+	if x < 0 {
+		x += t.Size
+	}
+	if y < 0 {
+		y += t.Size
+	}
+	return t.Thresholds[y*t.Size+x]
+}
+
+// fromRanks creates matrix from ranks 0 to size*size-1
+func fromRanks(size int, ranks []int) ThresholdMatrix {
+	t := ThresholdMatrix{size, make([]float64, size*size)}
+	for i, r := range ranks {
+		t.Thresholds[i] = (float64(r) + 0.5) / float64(size*size)
+	}
+	return t
+}
+
+// Bayer returns Bayer matrix of given size, which should be power of two (2, 4, 8 or 16)
+func Bayer(size int) ThresholdMatrix {
+	ranks := []int{0}
+	for n := 1; n < size; n *= 2 {
+		next := make([]int, 4*n*n)
+		for y := 0; y < n; y++ {
+			for x := 0; x < n; x++ {
+				r := 4 * ranks[y*n+x]
+				next[y*2*n+x] = r
+				next[y*2*n+x+n] = r + 2
+				next[(y+n)*2*n+x] = r + 3
+				next[(y+n)*2*n+x+n] = r + 1
+			}
+		}
+		ranks = next
+	}
+	return fromRanks(int(math.Sqrt(float64(len(ranks)))), ranks)
+}
+
+// BlueNoise returns blue noise matrix of given size generated by void-and-cluster method.
+// The result is always the same for the same size, but the generation is not cheap,
+// so create the matrix once and reuse it.
+func BlueNoise(size int) ThresholdMatrix {
+	n := size * size
+	const sigma = 1.5
+	// precomputed toroidal gaussian by distance in both axes
+	gauss := make([]float64, size*size)
+	for dy := 0; dy < size; dy++ {
+		for dx := 0; dx < size; dx++ {
+			x, y := math.Min(float64(dx), float64(size-dx)), math.Min(float64(dy), float64(size-dy))
+			gauss[dy*size+dx] = math.Exp(-(x*x + y*y) / (2 * sigma * sigma))
+		}
+	}
+	ones := make([]bool, n)
+	energy := make([]float64, n)
+	toggle := func(i int, on bool) {
+		ones[i] = on
+		sign := 1.0
+		if !on {
+			sign = -1
+		}
+		ix, iy := i%size, i/size
+		for j := range energy {
+			dx, dy := (j%size-ix+size)%size, (j/size-iy+size)%size
+			energy[j] += sign * gauss[dy*size+dx]
+		}
+	}
+	// tightest cluster is the one with highest energy, largest void is the empty one with lowest
+	extreme := func(on bool) int {
+		best := -1
+		for i := range energy {
+			if ones[i] == on && (best < 0 || on && energy[i] > energy[best] || !on && energy[i] < energy[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+
+	// initial pattern, randomly placed tenth of pixels spread by moving clusters to voids
+	rnd := rand.New(rand.NewSource(int64(size)))
+	initial := n / 10
+	if initial == 0 {
+		initial = 1
+	}
+	for count := 0; count < initial; {
+		if i := rnd.Intn(n); !ones[i] {
+			toggle(i, true)
+			count++
+		}
+	}
+	for limit := 0; limit < n; limit++ {
+		cluster := extreme(true)
+		toggle(cluster, false)
+		void := extreme(false)
+		if void == cluster {
+			toggle(cluster, true)
+			break
+		}
+		toggle(void, true)
+	}
+	pattern := append([]bool{}, ones...)
+	patternEnergy := append([]float64{}, energy...)
+
+	ranks := make([]int, n)
+	// ranks of initial pattern, removing tightest clusters first
+	for rank := initial - 1; rank >= 0; rank-- {
+		i := extreme(true)
+		toggle(i, false)
+		ranks[i] = rank
+	}
+	// ranks of the rest, filling largest voids first
+	copy(ones, pattern)
+	copy(energy, patternEnergy)
+	for rank := initial; rank < n; rank++ {
+		i := extreme(false)
+		toggle(i, true)
+		ranks[i] = rank
+	}
+	return fromRanks(size, ranks)
+}
+
+// Ordered converts images to black and white by comparing each pixel with threshold matrix.
+//
+// Thresholds depend on the position of the pixel only, so the same color
+// always results in the same pattern, which does not shimmer between partial updates.
+// It implements image/draw.Drawer interface.
+type Ordered struct {
+	Matrix    ThresholdMatrix // Bayer(4) if not set
+	Gamma     float64         // brightness is raised to the power of Gamma, 0 means 1
+	Luminance Luminance       // Average if not set
+}
+
+var bayer4 = Bayer(4)
+
+// Draw implements image/draw.Drawer interface
+func (o Ordered) Draw(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) {
+	r, sp = clip(dst, r, src, sp)
+	matrix := o.Matrix
+	if matrix.Size == 0 {
+		matrix = bayer4
+	}
+	gamma := o.Gamma
+	if gamma == 0 {
+		gamma = 1
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			v := o.Luminance.Gray(src.At(sp.X+x-r.Min.X, sp.Y+y-r.Min.Y))
+			if gamma != 1 {
+				v = math.Pow(v, gamma)
+			}
+			if v > matrix.At(x, y) {
+				dst.Set(x, y, color.White)
+			} else {
+				dst.Set(x, y, color.Black)
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"sort"
+	"testing"
+)
+
+func TestBayer(t *testing.T) {
+	b := Bayer(2)
+	for i, v := range []float64{0.125, 0.625, 0.875, 0.375} {
+		if b.Thresholds[i] != v {
+			t.Errorf("Bayer(2) = %v", b.Thresholds)
+			break
+		}
+	}
+	for _, size := range []int{2, 4, 8, 16} {
+		checkRanks(t, "bayer", Bayer(size), size)
+	}
+}
+
+func TestBlueNoise(t *testing.T) {
+	for _, size := range []int{8, 16} {
+		checkRanks(t, "blue noise", BlueNoise(size), size)
+	}
+}
+
+// checkRanks verifies that matrix contains each threshold exactly once
+func checkRanks(t *testing.T, name string, m ThresholdMatrix, size int) {
+	if m.Size != size || len(m.Thresholds) != size*size {
+		t.Errorf("%s(%d) has size %d and %d thresholds", name, size, m.Size, len(m.Thresholds))
+		return
+	}
+	sorted := append([]float64{}, m.Thresholds...)
+	sort.Float64s(sorted)
+	for i, v := range sorted {
+		if v != (float64(i)+0.5)/float64(size*size) {
+			t.Errorf("%s(%d) does not contain every rank once", name, size)
+			return
+		}
+	}
+}
+
+func TestOrdered(t *testing.T) {
+	rect := image.Rect(0, 0, 32, 32)
+	for name, o := range map[string]Ordered{
+		"default":    {},
+		"bayer16":    {Matrix: Bayer(16)},
+		"blue noise": {Matrix: BlueNoise(16)},
+	} {
+		for _, tc := range []struct {
+			gray     uint8
+			min, max int
+		}{
+			{0x00, 0, 0},
+			{0xFF, 32 * 32, 32 * 32},
+			{0x80, 32 * 32 / 2, 32*32/2 + 32*32/256}, // 0x80 is slightly above half
+		} {
+			m := NewMono(rect)
+			o.Draw(&m, rect, image.NewUniform(color.Gray{tc.gray}), image.ZP)
+			if n := countWhite(m); n < tc.min || n > tc.max {
+				t.Errorf("%s: %d white pixels for gray %X", name, n, tc.gray)
+			}
+		}
+	}
+}
+
+func TestOrderedStable(t *testing.T) {
+	rect := image.Rect(0, 0, 32, 32)
+	gray := image.NewUniform(color.Gray{0x60})
+	whole, parts := NewMono(rect), NewMono(rect)
+	o := Ordered{Matrix: BlueNoise(8)}
+	o.Draw(&whole, rect, gray, image.ZP)
+	o.Draw(&parts, image.Rect(0, 0, 13, 32), gray, image.ZP)
+	o.Draw(&parts, image.Rect(13, 0, 32, 32), gray, image.ZP)
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			if whole.At(x, y) != parts.At(x, y) {
+				t.Fatalf("pixel %d,%d differs when drawn in parts", x, y)
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package image
+
+import (
+	"image"
+	"testing"
+)
+
+func TestTranspose8(t *testing.T) {
+	for seed := int64(0); seed < 10; seed++ {
+		m := randomMono(image.Rect(0, 0, 8, 8), seed)
+		var x uint64
+		for _, b := range m.Bitmap() {
+			x = x<<8 | uint64(b)
+		}
+		x = transpose8(x)
+		for i := 0; i < 8; i++ {
+			for k := 0; k < 8; k++ {
+				if bit := x>>uint(63-8*k-i)&1 == 1; bit != m.Bit(k, i) {
+					t.Fatalf("bit %d,%d not transposed", k, i)
+				}
+			}
+		}
+	}
+}
+
+func TestRotate(t *testing.T) {
+	for _, size := range []image.Point{{296, 128}, {13, 5}, {8, 8}, {1, 17}, {17, 1}, {3, 3}} {
+		m := randomMono(image.Rectangle{Max: size}, 4)
+		w, h := size.X, size.Y
+		// clear padding, so it can be compared
+		for y := 0; y < h; y++ {
+			m.fillSpan(w, m.stride()*8, y, false)
+		}
+
+		for name, tc := range map[string]struct {
+			rotate func(*Mono)
+			size   image.Point
+			at     func(x, y int) (int, int) // where pixel x, y goes
+		}{
+			"right": {(*Mono).RotateRight, image.Pt(h, w), func(x, y int) (int, int) { return h - 1 - y, x }},
+			"left":  {(*Mono).RotateLeft, image.Pt(h, w), func(x, y int) (int, int) { return y, w - 1 - x }},
+			"180":   {(*Mono).Rotate180, size, func(x, y int) (int, int) { return w - 1 - x, h - 1 - y }},
+		} {
+			n := append(Mono(nil), m...)
+			tc.rotate(&n)
+			if n.Bounds().Size() != tc.size {
+				t.Errorf("%v %s: size %v", size, name, n.Bounds().Size())
+				continue
+			}
+			for y := 0; y < h; y++ {
+				for x := 0; x < w; x++ {
+					if nx, ny := tc.at(x, y); n.Bit(nx, ny) != m.Bit(x, y) {
+						t.Fatalf("%v %s: pixel %d,%d not at %d,%d", size, name, x, y, nx, ny)
+					}
+				}
+			}
+			for y := 0; y < tc.size.Y; y++ { // padding is kept clear
+				for x := tc.size.X; x < n.stride()*8; x++ {
+					if n[4+y*n.stride()+x/8]&(0x80>>uint(x%8)) != 0 {
+						t.Fatalf("%v %s: padding bit %d,%d set", size, name, x, y)
+					}
+				}
+			}
+		}
+	}
+}
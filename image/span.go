@@ -0,0 +1,109 @@
+package image
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// fill sets all pixels of r clipped by the view to given color
+func (m *MonoView) fill(r image.Rectangle, c color.Color) {
+	r = r.Intersect(m.rect).Add(m.delta)
+	white := isWhite(c)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		m.m.fillSpan(r.Min.X, r.Max.X, y, white)
+	}
+}
+
+// fillSpan sets pixels from x0 to x1 (exclusive) on row y,
+// partial bytes on the edges are masked, whole bytes are set by words
+func (m Mono) fillSpan(x0, x1, y int, white bool) {
+	if x0 >= x1 {
+		return
+	}
+	stride := m.stride()
+	row := m[4+y*stride : 4+(y+1)*stride]
+	first, last := x0/8, (x1-1)/8
+	left := byte(0xFF) >> uint(x0%8)
+	right := byte(0xFF) << uint(7-(x1-1)%8)
+	if first == last {
+		setMasked(&row[first], left&right, white)
+		return
+	}
+	setMasked(&row[first], left, white)
+	setMasked(&row[last], right, white)
+	if white {
+		fillBytes(row[first+1:last], 0xFF)
+	} else {
+		fillBytes(row[first+1:last], 0x00)
+	}
+}
+
+func setMasked(b *byte, mask byte, white bool) {
+	if white {
+		*b |= mask
+	} else {
+		*b &^= mask
+	}
+}
+
+// fillBytes sets all bytes to v, 8 bytes at once
+func fillBytes(b []byte, v byte) {
+	word := uint64(v) * 0x0101010101010101
+	for len(b) >= 8 {
+		binary.LittleEndian.PutUint64(b, word)
+		b = b[8:]
+	}
+	for i := range b {
+		b[i] = v
+	}
+}
+
+// Drawer draws images like draw.Draw with draw.Src operator does.
+//
+// It takes fast path when dst is Mono or MonoView and src is
+// uniform color (image.Uniform), Mono or MonoView,
+// other images are drawn pixel by pixel.
+var Drawer draw.Drawer = drawer{}
+
+type drawer struct{}
+
+// Draw implements image/draw.Drawer interface
+func (drawer) Draw(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) {
+	d := asView(dst)
+	if d == nil {
+		draw.Draw(dst, r, src, sp, draw.Src)
+		return
+	}
+	if u, ok := src.(*image.Uniform); ok {
+		d.fill(r, u.C)
+		return
+	}
+	s := asView(src)
+	if s == nil {
+		draw.Draw(dst, r, src, sp, draw.Src)
+		return
+	}
+	r, sp = clip(dst, r, src, sp)
+	if !r.Empty() {
+		blit(d.m, r.Add(d.delta), s.m, sp.Add(s.delta), RasterCopy)
+	}
+}
+
+// asView returns view of the whole image if it is Mono, nil for other images
+func asView(img image.Image) *MonoView {
+	switch m := img.(type) {
+	case Mono:
+		return m.view()
+	case *Mono:
+		return m.view()
+	case *MonoView:
+		return m
+	}
+	return nil
+}
+
+func sameBitmap(a, b Mono) bool {
+	return len(a) > 0 && len(b) > 0 && &a[0] == &b[0]
+}
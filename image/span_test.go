@@ -0,0 +1,104 @@
+package image
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestSetBit(t *testing.T) {
+	m := NewMono(image.Rect(0, 0, 10, 2))
+	m.SetBit(9, 1, true)
+	m.SetBit(10, 1, true) // padding is not touched
+	m.SetBit(-1, 0, true)
+	if want := []byte{0, 10, 0, 2, 0, 0, 0, 0x40}; !bytes.Equal(m, want) {
+		t.Errorf("got %X, want %X", []byte(m), want)
+	}
+	if !m.Bit(9, 1) || m.Bit(8, 1) || m.Bit(10, 1) {
+		t.Errorf("wrong bits")
+	}
+}
+
+func TestFillSpan(t *testing.T) {
+	for x0 := 0; x0 < 40; x0++ {
+		for x1 := x0; x1 <= 40; x1++ {
+			m := NewMono(image.Rect(0, 0, 43, 1))
+			m.Clear(color.Black)
+			m.fillSpan(x0, x1, 0, true)
+			for x := 0; x < 43; x++ {
+				if m.Bit(x, 0) != (x >= x0 && x < x1) {
+					t.Fatalf("span %d-%d: wrong pixel %d", x0, x1, x)
+				}
+			}
+			m.Clear(color.White)
+			m.fillSpan(x0, x1, 0, false)
+			if n := countWhite(m); n != 43-(x1-x0) {
+				t.Fatalf("span %d-%d: %d white pixels", x0, x1, n)
+			}
+		}
+	}
+}
+
+func TestFillRect(t *testing.T) {
+	m := NewMono(image.Rect(0, 0, 30, 20))
+	m.FillRect(color.White, image.Rect(3, 4, 21, 9))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 30; x++ {
+			in := x >= 3 && x <= 21 && y >= 4 && y <= 9 // including Max
+			if m.Bit(x, y) != in {
+				t.Fatalf("wrong pixel %d,%d", x, y)
+			}
+		}
+	}
+	m.DrawHorizontalLine(color.White, image.Pt(0, 0), -2)
+	m.DrawVerticalLine(color.White, image.Pt(0, 0), -1)
+	if m.Bit(0, 0) {
+		t.Errorf("line of negative length was drawn")
+	}
+}
+
+func TestDrawer(t *testing.T) {
+	src := NewMono(image.Rect(0, 0, 16, 16))
+	ErrorDiffusion{}.Draw(&src, src.Bounds(), image.NewUniform(color.Gray{0x70}), image.ZP)
+
+	for _, r := range []image.Rectangle{
+		image.Rect(0, 0, 20, 20),
+		image.Rect(3, 5, 17, 11),
+		image.Rect(-4, 2, 9, 30),
+	} {
+		for _, sp := range []image.Point{{0, 0}, {5, 1}, {-2, 3}} {
+			fast, slow := NewMono(image.Rect(0, 0, 20, 20)), NewMono(image.Rect(0, 0, 20, 20))
+			fast.Clear(color.White)
+			slow.Clear(color.White)
+			Drawer.Draw(&fast, r, src, sp)
+			draw.Draw(&slow, r, src, sp, draw.Src)
+			if !bytes.Equal(fast, slow) {
+				t.Errorf("copy %v from %v: got %X, want %X", r, sp, []byte(fast), []byte(slow))
+			}
+			Drawer.Draw(&fast, r, image.Black, sp)
+			draw.Draw(&slow, r, image.Black, sp, draw.Src)
+			if !bytes.Equal(fast, slow) {
+				t.Errorf("fill %v: got %X, want %X", r, []byte(fast), []byte(slow))
+			}
+		}
+	}
+}
+
+func TestDrawerOverlap(t *testing.T) {
+	for _, d := range []image.Point{{3, 0}, {-3, 0}, {0, 2}, {0, -2}, {5, 5}, {-5, -5}, {3, -2}} {
+		m := NewMono(image.Rect(0, 0, 24, 12))
+		ErrorDiffusion{}.Draw(&m, m.Bounds(), image.NewUniform(color.Gray{0x70}), image.ZP)
+		r := image.Rect(6, 4, 18, 8)
+
+		// copy within the same bitmap without intermediate buffer
+		orig := append(Mono(nil), m...)
+		Drawer.Draw(&m, r.Add(d), m, r.Min)
+		check := append(Mono(nil), orig...)
+		draw.Draw(&check, r.Add(d), orig, r.Min, draw.Src)
+		if !bytes.Equal(m, check) {
+			t.Errorf("shift by %v: got %X, want %X", d, []byte(m), []byte(check))
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// SetColorModel replaces model used by Mono to convert colors to black and white,
+// model should convert each color to either color.Black or color.White.
+// nil restores the default fixed threshold.
+// It is safe to call while other goroutines draw.
+func SetColorModel(model color.Model) {
+	if model == nil {
+		model = defaultColorModel
+	}
+	currentModel.Store(modelHolder{model})
+}
+
+// Threshold converts colors to black and white by comparing their brightness with fixed Level.
+//
+// It implements both color.Model, so it can be passed to SetColorModel,
+// and image/draw.Drawer interface.
+type Threshold struct {
+	Level     float64   // brightness in range 0-1 above which color is white, 0 means 0.5
+	Luminance Luminance // Average if not set
+}
+
+func (t Threshold) level() float64 {
+	if t.Level == 0 {
+		return 0.5
+	}
+	return t.Level
+}
+
+// Convert implements color.Model interface
+func (t Threshold) Convert(c color.Color) color.Color {
+	if t.Luminance.Gray(c) < t.level() {
+		return color.Black
+	}
+	return color.White
+}
+
+// Draw implements image/draw.Drawer interface
+func (t Threshold) Draw(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) {
+	r, sp = clip(dst, r, src, sp)
+	level := t.level()
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if t.Luminance.Gray(src.At(sp.X+x-r.Min.X, sp.Y+y-r.Min.Y)) < level {
+				dst.Set(x, y, color.Black)
+			} else {
+				dst.Set(x, y, color.White)
+			}
+		}
+	}
+}
+
+// Otsu converts images to black and white by global threshold
+// chosen by Otsu's method from histogram of the source region.
+// It suits screenshots and clean scans with two distinct tones.
+//
+// It implements image/draw.Drawer interface.
+type Otsu struct {
+	Luminance Luminance // Average if not set
+}
+
+// Draw implements image/draw.Drawer interface
+func (o Otsu) Draw(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) {
+	r, sp = clip(dst, r, src, sp)
+	level := OtsuLevel(src, r.Sub(r.Min).Add(sp), o.Luminance)
+	Threshold{level, o.Luminance}.Draw(dst, r, src, sp)
+}
+
+// OtsuLevel returns threshold level in range 0-1 which best separates
+// dark and light pixels of src within r
+func OtsuLevel(src image.Image, r image.Rectangle, l Luminance) float64 {
+	var hist [256]int
+	r = r.Intersect(src.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			hist[int(l.Gray(src.At(x, y))*255+0.5)]++
+		}
+	}
+	total := r.Dx() * r.Dy()
+	sum := 0.0
+	for i, n := range hist {
+		sum += float64(i * n)
+	}
+
+	best, level := -1.0, 0.5
+	sumDark, dark := 0.0, 0
+	for i, n := range hist {
+		dark += n
+		if dark == 0 {
+			continue
+		}
+		light := total - dark
+		if light == 0 {
+			break
+		}
+		sumDark += float64(i * n)
+		meanDark := sumDark / float64(dark)
+		meanLight := (sum - sumDark) / float64(light)
+		between := float64(dark) * float64(light) * (meanDark - meanLight) * (meanDark - meanLight)
+		if between > best {
+			best, level = between, (float64(i)+0.5)/255 // pixels of level i are still dark
+		}
+	}
+	return level
+}
+
+// Adaptive converts images to black and white by comparing each pixel
+// with mean brightness of its neighbourhood.
+// It keeps text legible on unevenly lit scans and photos of documents.
+//
+// It implements image/draw.Drawer interface.
+type Adaptive struct {
+	Radius    int       // neighbourhood is square of 2*Radius+1 pixels, 0 means 7
+	Gaussian  bool      // weight neighbours by Gaussian with sigma Radius/2 instead of plain mean
+	Offset    float64   // pixel is black if darker than local mean minus Offset
+	Luminance Luminance // Average if not set
+}
+
+// Draw implements image/draw.Drawer interface
+func (a Adaptive) Draw(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) {
+	r, sp = clip(dst, r, src, sp)
+	if r.Empty() {
+		return
+	}
+	radius := a.Radius
+	if radius == 0 {
+		radius = 7
+	}
+
+	w, h := r.Dx(), r.Dy()
+	gray := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			gray[y*w+x] = a.Luminance.Gray(src.At(sp.X+x, sp.Y+y))
+		}
+	}
+	var local []float64
+	if a.Gaussian {
+		local = gaussianBlur(gray, w, h, radius)
+	} else {
+		local = boxBlur(gray, w, h, radius)
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if gray[y*w+x] < local[y*w+x]-a.Offset-1e-9 { // rounding errors of uniform areas
+				dst.Set(r.Min.X+x, r.Min.Y+y, color.Black)
+			} else {
+				dst.Set(r.Min.X+x, r.Min.Y+y, color.White)
+			}
+		}
+	}
+}
+
+// boxBlur returns mean of each pixel's neighbourhood computed from summed-area table,
+// neighbourhood is cropped on the edges
+func boxBlur(gray []float64, w, h, radius int) []float64 {
+	sums := make([]float64, (w+1)*(h+1))
+	for y := 0; y < h; y++ {
+		row := 0.0
+		for x := 0; x < w; x++ {
+			row += gray[y*w+x]
+			sums[(y+1)*(w+1)+x+1] = sums[y*(w+1)+x+1] + row
+		}
+	}
+	mean := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		y0, y1 := clamp(y-radius, 0, h), clamp(y+radius+1, 0, h)
+		for x := 0; x < w; x++ {
+			x0, x1 := clamp(x-radius, 0, w), clamp(x+radius+1, 0, w)
+			sum := sums[y1*(w+1)+x1] - sums[y0*(w+1)+x1] - sums[y1*(w+1)+x0] + sums[y0*(w+1)+x0]
+			mean[y*w+x] = sum / float64((x1-x0)*(y1-y0))
+		}
+	}
+	return mean
+}
+
+// gaussianBlur returns weighted mean of each pixel's neighbourhood,
+// kernel is separable so rows and columns are blurred in two passes
+func gaussianBlur(gray []float64, w, h, radius int) []float64 {
+	sigma := float64(radius) / 2
+	kernel := make([]float64, 2*radius+1)
+	for i := range kernel {
+		d := float64(i - radius)
+		kernel[i] = math.Exp(-d * d / (2 * sigma * sigma))
+	}
+	pass := func(in []float64, n, count, step, stride int) []float64 {
+		out := make([]float64, len(in))
+		for j := 0; j < count; j++ {
+			for i := 0; i < n; i++ {
+				sum, weight := 0.0, 0.0
+				for k, kw := range kernel {
+					p := i + k - radius
+					if p < 0 || p >= n {
+						continue // renormalized on the edges
+					}
+					sum += in[j*stride+p*step] * kw
+					weight += kw
+				}
+				out[j*stride+i*step] = sum / weight
+			}
+		}
+		return out
+	}
+	rows := pass(gray, w, h, 1, w)
+	return pass(rows, h, w, w, 1)
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,122 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestSetColorModel(t *testing.T) {
+	defer SetColorModel(nil)
+	gray := color.Gray{0x60}
+
+	m := NewMono(image.Rect(0, 0, 8, 1))
+	m.Set(0, 0, gray)
+	if m.At(0, 0) != color.Black {
+		t.Errorf("default model: gray %X should be black", gray.Y)
+	}
+	SetColorModel(Threshold{Level: 0.25})
+	m.Set(0, 0, gray)
+	if m.At(0, 0) != color.White {
+		t.Errorf("threshold 0.25: gray %X should be white", gray.Y)
+	}
+	SetColorModel(nil)
+	m.Set(0, 0, gray)
+	if m.At(0, 0) != color.Black {
+		t.Errorf("restored model: gray %X should be black", gray.Y)
+	}
+}
+
+func TestSetColorModelWhileDrawing(t *testing.T) {
+	defer SetColorModel(nil)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m := NewMono(image.Rect(0, 0, 8, 8))
+		for i := 0; i < 100; i++ {
+			m.Set(i%8, i/8%8, color.Gray{0x60})
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		SetColorModel(Threshold{Level: 0.25})
+		SetColorModel(nil)
+	}
+	<-done
+}
+
+func TestThresholdLuminance(t *testing.T) {
+	green := color.RGBA{0, 0xC0, 0, 0xFF} // dark by average, light by perceived brightness
+	if c := (Threshold{}).Convert(green); c != color.Black {
+		t.Errorf("average: got %v", c)
+	}
+	if c := (Threshold{Luminance: Rec709}).Convert(green); c != color.White {
+		t.Errorf("rec709: got %v", c)
+	}
+}
+
+// twoTone returns image with dark text-like squares on light background
+func twoTone(dark, light uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 64, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 64; x++ {
+			v := light
+			if x%16 < 4 && y%16 < 4 {
+				v = dark
+			}
+			img.SetGray(x, y, color.Gray{v})
+		}
+	}
+	return img
+}
+
+func TestOtsu(t *testing.T) {
+	for _, tc := range []struct{ dark, light uint8 }{
+		{0x10, 0x30}, // both below fixed threshold
+		{0xC0, 0xF0}, // both above fixed threshold
+	} {
+		img := twoTone(tc.dark, tc.light)
+		level := OtsuLevel(img, img.Bounds(), Luminance{})
+		if level <= float64(tc.dark)/255 || level > float64(tc.light)/255 {
+			t.Errorf("level %.3f not between %X and %X", level, tc.dark, tc.light)
+		}
+		m := NewMono(img.Bounds())
+		Otsu{}.Draw(&m, m.Bounds(), img, image.ZP)
+		if n := countWhite(m); n != 64*32-8*4*4 {
+			t.Errorf("%X/%X: %d white pixels", tc.dark, tc.light, n)
+		}
+	}
+}
+
+func TestAdaptive(t *testing.T) {
+	// background fades from dark to light, marks are slightly darker than their surroundings
+	img := image.NewGray(image.Rect(0, 0, 128, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 128; x++ {
+			v := 0x20 + x
+			if x%16 >= 6 && x%16 < 10 && y >= 14 && y < 18 {
+				v -= 0x18
+			}
+			img.SetGray(x, y, color.Gray{uint8(v)})
+		}
+	}
+	for _, gaussian := range []bool{false, true} {
+		m := NewMono(img.Bounds())
+		Adaptive{Gaussian: gaussian, Offset: 0.02}.Draw(&m, m.Bounds(), img, image.ZP)
+		for y := 0; y < 32; y++ {
+			for x := 0; x < 128; x++ {
+				mark := x%16 >= 6 && x%16 < 10 && y >= 14 && y < 18
+				if white := m.At(x, y) == color.White; white == mark {
+					t.Fatalf("gaussian %v: pixel %d,%d white %v", gaussian, x, y, white)
+				}
+			}
+		}
+	}
+}
+
+func TestAdaptiveUniform(t *testing.T) {
+	m := NewMono(image.Rect(0, 0, 32, 32))
+	Adaptive{}.Draw(&m, m.Bounds(), image.NewUniform(color.Gray{0x40}), image.ZP)
+	if n := countWhite(m); n != 32*32 {
+		t.Errorf("%d white pixels in uniform area", n)
+	}
+}
@@ -0,0 +1,152 @@
+package image
+
+import (
+	"image"
+	"image/color"
+)
+
+// MonoView is rectangular region of Mono which shares its bitmap.
+//
+// Its bounds may start anywhere, like bounds of image.Gray do,
+// drawing outside of the bounds is ignored.
+// It implements image.Image and image/draw.Image interface.
+type MonoView struct {
+	m     Mono
+	rect  image.Rectangle // bounds in coordinates of the view
+	delta image.Point     // converts coordinates of the view to coordinates of m
+}
+
+// NewMonoView creates new white-black image with bounds given by rect
+func NewMonoView(rect image.Rectangle) *MonoView {
+	return &MonoView{NewMono(rect), rect, image.ZP.Sub(rect.Min)}
+}
+
+// view returns view of the whole image
+func (m Mono) view() *MonoView {
+	return &MonoView{m, m.Bounds(), image.ZP}
+}
+
+// SubImage returns view of the part of the image visible through r,
+// coordinates are the same as in the image.
+func (m Mono) SubImage(r image.Rectangle) image.Image {
+	return m.view().SubImage(r)
+}
+
+// SubImage returns view of the part of the view visible through r,
+// coordinates are the same as in the view.
+func (m *MonoView) SubImage(r image.Rectangle) image.Image {
+	return &MonoView{m.m, r.Intersect(m.rect), m.delta}
+}
+
+// Origin returns the same view with coordinates moved, so its top left corner is at p.
+// Widgets can draw into view returned by Origin(image.ZP) in their local coordinates.
+func (m *MonoView) Origin(p image.Point) *MonoView {
+	d := p.Sub(m.rect.Min)
+	return &MonoView{m.m, m.rect.Add(d), m.delta.Sub(d)}
+}
+
+// Mono returns image which holds the bitmap of the view
+func (m *MonoView) Mono() Mono {
+	return m.m
+}
+
+// Bounds returns Rectangle bounding the view.
+//
+// Implements image.Image interface.
+func (m *MonoView) Bounds() image.Rectangle {
+	return m.rect
+}
+
+// ColorModel return color.Model of the view, same as of Mono.
+//
+// Implements image.Image interface.
+func (m *MonoView) ColorModel() color.Model {
+	return colorModel()
+}
+
+// At returns color at given coordinates, color.Black outside of the bounds.
+//
+// Implements image.Image interface.
+func (m *MonoView) At(x, y int) color.Color {
+	if !(image.Point{x, y}.In(m.rect)) {
+		return color.Black
+	}
+	return m.m.At(x+m.delta.X, y+m.delta.Y)
+}
+
+// SetBit sets pixel on given coordinates to white (true) or black (false)
+// without any color conversion
+func (m *MonoView) SetBit(x, y int, white bool) {
+	if !(image.Point{x, y}.In(m.rect)) {
+		return
+	}
+	m.m.SetBit(x+m.delta.X, y+m.delta.Y, white)
+}
+
+// Bit returns true if pixel on given coordinates is white,
+// pixels outside of the view are black
+func (m *MonoView) Bit(x, y int) bool {
+	if !(image.Point{x, y}.In(m.rect)) {
+		return false
+	}
+	return m.m.Bit(x+m.delta.X, y+m.delta.Y)
+}
+
+// Set sets color on given coordinates, nothing happens outside of the bounds.
+//
+// Implements image/draw.Image interface.
+func (m *MonoView) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(m.rect)) {
+		return
+	}
+	m.m.SetBit(x+m.delta.X, y+m.delta.Y, isWhite(c))
+}
+
+// Clear sets whole view to given color - color.Black or color.White
+func (m *MonoView) Clear(c color.Color) {
+	m.fill(m.rect, c)
+}
+
+// DrawHorizontalLine draws horizontal line given by left most point and length,
+// see MonoView.DrawHorizontalLine
+func (m *Mono) DrawHorizontalLine(color color.Color, start image.Point, length int) {
+	m.view().DrawHorizontalLine(color, start, length)
+}
+
+// DrawVerticalLine draws vertical line given by top most point and length,
+// see MonoView.DrawVerticalLine
+func (m *Mono) DrawVerticalLine(color color.Color, start image.Point, length int) {
+	m.view().DrawVerticalLine(color, start, length)
+}
+
+// DrawLine draws arbitrary line, see MonoView.DrawLine
+func (m *Mono) DrawLine(color color.Color, start image.Point, end image.Point) {
+	m.view().DrawLine(color, start, end)
+}
+
+// StrokeRect draws outline of rectangle
+func (m *Mono) StrokeRect(color color.Color, rect image.Rectangle) {
+	m.view().StrokeRect(color, rect)
+}
+
+// FillRect draws filled rectangle
+func (m *Mono) FillRect(color color.Color, rect image.Rectangle) {
+	m.view().FillRect(color, rect)
+}
+
+// StrokeCircle draws outline of circle given by center point and radius,
+// see MonoView.StrokeCircle
+func (m *Mono) StrokeCircle(color color.Color, center image.Point, radius int) {
+	m.view().StrokeCircle(color, center, radius)
+}
+
+// FillCircle draws filled circle given by center point and radius,
+// see MonoView.FillCircle
+func (m *Mono) FillCircle(color color.Color, center image.Point, radius int) {
+	m.view().FillCircle(color, center, radius)
+}
+
+// DrawString writes text using Go font with baseline starting at dot
+func (m *Mono) DrawString(color color.Color, text string, size float64, dot image.Point) {
+	m.view().DrawString(color, text, size, dot)
+}
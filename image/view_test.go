@@ -0,0 +1,69 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestNewMonoView(t *testing.T) {
+	r := image.Rect(-5, 10, 11, 14)
+	v := NewMonoView(r)
+	if v.Bounds() != r {
+		t.Errorf("bounds %v, want %v", v.Bounds(), r)
+	}
+	v.Clear(color.White)
+	v.Set(-5, 10, color.Black)
+	v.Set(10, 13, color.Black)
+	v.Set(11, 13, color.Black) // outside
+	if n := countWhite(v.Mono()); n != 16*4-2 {
+		t.Errorf("%d white pixels", n)
+	}
+	if v.Mono().At(0, 0) != color.Black || v.Mono().At(15, 3) != color.Black {
+		t.Errorf("corners not set")
+	}
+}
+
+func TestNewMonoOrigin(t *testing.T) {
+	r := image.Rect(-5, 10, 11, 14)
+	if b := NewMono(r).Bounds(); b != image.Rect(0, 0, 16, 4) {
+		t.Errorf("Mono bounds %v, origin should be dropped", b)
+	}
+	if b := NewMonoView(r).Mono().Bounds(); b != image.Rect(0, 0, 16, 4) {
+		t.Errorf("bitmap of the view has bounds %v", b)
+	}
+}
+
+func TestSubImage(t *testing.T) {
+	m := NewMono(image.Rect(0, 0, 32, 16))
+	m.Clear(color.White)
+	r := image.Rect(5, 3, 21, 9)
+	sub := m.SubImage(r).(*MonoView)
+	if sub.Bounds() != r {
+		t.Errorf("bounds %v, want %v", sub.Bounds(), r)
+	}
+	sub.FillRect(color.Black, image.Rect(0, 0, 40, 40)) // clipped by the view
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 32; x++ {
+			in := image.Pt(x, y).In(r)
+			if black := m.At(x, y) == color.Black; black != in {
+				t.Fatalf("pixel %d,%d black %v", x, y, black)
+			}
+		}
+	}
+
+	// nested view drawn in local coordinates
+	local := sub.SubImage(image.Rect(10, 5, 30, 30)).(*MonoView).Origin(image.ZP)
+	if want := image.Rect(0, 0, 11, 4); local.Bounds() != want {
+		t.Errorf("local bounds %v, want %v", local.Bounds(), want)
+	}
+	draw.Draw(local, local.Bounds(), image.NewUniform(color.White), image.ZP, draw.Src)
+	local.Set(0, 0, color.Black)
+	if m.At(10, 5) != color.Black || m.At(11, 5) != color.White || m.At(20, 8) != color.White || m.At(9, 5) != color.Black {
+		t.Errorf("local drawing misplaced")
+	}
+	if local.At(-1, 0) != color.Black {
+		t.Errorf("pixel outside of view is not black")
+	}
+}
@@ -0,0 +1,140 @@
+package image
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrXBM is returned for malformed XBM files
+var ErrXBM = errors.New("xbm: invalid format")
+
+func init() {
+	image.RegisterFormat("xbm", "#define", decodeXBM, decodeXBMConfig)
+}
+
+var (
+	xbmDefine = regexp.MustCompile(`#define\s+(\w*?)_?(width|height)\s+(\d+)`)
+	xbmBits   = regexp.MustCompile(`(?s)\w+_bits\s*\[\s*\]\s*=\s*\{(.*?)\}`)
+)
+
+type xbmHeader struct {
+	width, height int
+	body          string // text after the header
+}
+
+func readXBMHeader(text string) (h xbmHeader, err error) {
+	h.width, h.height = -1, -1
+	for _, match := range xbmDefine.FindAllStringSubmatch(text, 2) {
+		n, err := strconv.Atoi(match[3])
+		if err != nil || n > 0xFFFF {
+			return h, ErrSize
+		}
+		if match[2] == "width" {
+			h.width = n
+		} else {
+			h.height = n
+		}
+	}
+	if h.width < 0 || h.height < 0 {
+		return h, ErrXBM
+	}
+	return h, nil
+}
+
+func decodeXBMConfig(r io.Reader) (image.Config, error) {
+	// header is read line by line until both sizes or the bits are found,
+	// comments of any length may precede them
+	br := bufio.NewReader(r)
+	var text strings.Builder
+	for defines := 0; defines < 2; {
+		line, err := br.ReadString('\n')
+		text.WriteString(line)
+		if err == io.EOF || strings.Contains(line, "_bits") {
+			break
+		}
+		if err != nil {
+			return image.Config{}, err
+		}
+		defines += len(xbmDefine.FindAllString(line, -1))
+	}
+	h, err := readXBMHeader(text.String())
+	if err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{ColorModel: colorModel(), Width: h.width, Height: h.height}, nil
+}
+
+func decodeXBM(r io.Reader) (image.Image, error) {
+	return DecodeXBM(r)
+}
+
+// DecodeXBM reads X11 bitmap.
+//
+// XBM stores least significant bit first and 1 is black,
+// rows are padded to whole bytes the same way as in Mono, so padding bits are kept.
+func DecodeXBM(r io.Reader) (Mono, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text := string(data)
+	h, err := readXBMHeader(text)
+	if err != nil {
+		return nil, err
+	}
+	match := xbmBits.FindStringSubmatch(text)
+	if match == nil {
+		return nil, ErrXBM
+	}
+	values := strings.Split(match[1], ",")
+	if last := strings.TrimSpace(values[len(values)-1]); last == "" {
+		values = values[:len(values)-1] // trailing comma
+	}
+	if len(values) != monoLength(uint(h.width), uint(h.height)) {
+		return nil, ErrLength // checked before allocating, dimensions may be forged
+	}
+	m := NewMono(image.Rect(0, 0, h.width, h.height))
+	bitmap := m.Bitmap()
+	for i, v := range values {
+		b, err := strconv.ParseUint(strings.TrimSpace(v), 0, 8)
+		if err != nil {
+			return nil, ErrXBM
+		}
+		bitmap[i] = ^flipByte(byte(b))
+	}
+	return m, nil
+}
+
+// EncodeXBM writes image as X11 bitmap with given C identifier,
+// in the same layout as bitmap(1) does.
+// Images other than Mono are converted by its color model first.
+func EncodeXBM(w io.Writer, name string, img image.Image) error {
+	m, err := toMono(img)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "#define %s_width %d\n", name, m.Width())
+	fmt.Fprintf(bw, "#define %s_height %d\n", name, m.Height())
+	fmt.Fprintf(bw, "static unsigned char %s_bits[] = {", name)
+	for i, b := range m.Bitmap() {
+		if i > 0 {
+			bw.WriteString(",")
+		}
+		if i%12 == 0 {
+			bw.WriteString("\n   ")
+		} else {
+			bw.WriteString(" ")
+		}
+		fmt.Fprintf(bw, "0x%02x", ^flipByte(b))
+	}
+	bw.WriteString("};\n")
+	return bw.Flush()
+}
@@ -0,0 +1,95 @@
+package image
+
+import (
+	"bytes"
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestXBMRoundTrip(t *testing.T) {
+	xbm := `#define dot_width 10
+#define dot_height 2
+static unsigned char dot_bits[] = {
+   0x01, 0xfc, 0xaa, 0x02};
+`
+	img, name, err := image.Decode(strings.NewReader(xbm))
+	if err != nil || name != "xbm" {
+		t.Fatalf("%q %v", name, err)
+	}
+	m := img.(Mono)
+	if want := []byte{0, 10, 0, 2, 0x7F, 0xC0, 0xAA, 0xBF}; !bytes.Equal(m, want) {
+		t.Errorf("decoded %X, want %X", []byte(m), want)
+	}
+	var buf bytes.Buffer
+	if err := EncodeXBM(&buf, "dot", m); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != xbm {
+		t.Errorf("encoded %q, want %q", buf.String(), xbm)
+	}
+}
+
+func TestXBMLongRows(t *testing.T) {
+	m := NewMono(image.Rect(0, 0, 100, 3))
+	for i := range m.Bitmap() {
+		m.Bitmap()[i] = byte(i * 7)
+	}
+	var buf bytes.Buffer
+	if err := EncodeXBM(&buf, "big", m); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 3+4 { // 39 bytes by 12 per line
+		t.Errorf("%d lines:\n%s", lines, buf.String())
+	}
+	n, err := DecodeXBM(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(n, m) {
+		t.Errorf("decoded %X, want %X", []byte(n), []byte(m))
+	}
+}
+
+func TestXBMConfigLongComment(t *testing.T) {
+	xbm := "#define logo_width 10\n/*" + strings.Repeat(" license text\n", 100) + "*/\n" +
+		"#define logo_height 2\nstatic unsigned char logo_bits[] = {0x00, 0x00, 0x00, 0x00};\n"
+	config, name, err := image.DecodeConfig(strings.NewReader(xbm))
+	if err != nil || name != "xbm" {
+		t.Fatalf("%q %v", name, err)
+	}
+	if config.Width != 10 || config.Height != 2 {
+		t.Errorf("config %dx%d, want 10x2", config.Width, config.Height)
+	}
+	if _, err := DecodeXBM(strings.NewReader(xbm)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDecodeXBMInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		data string
+		err  error
+	}{
+		{"#define a_width 8\nstatic char a_bits[] = {0x00};", ErrXBM},
+		{"#define a_width 8\n#define a_height 2\nstatic char a_bits[] = {0x00};", ErrLength},
+		{"#define a_width 8\n#define a_height 1\nstatic char a_bits[] = {0x100};", ErrXBM},
+		{"#define a_width 8\n#define a_height 1\n", ErrXBM},
+	} {
+		if _, err := DecodeXBM(strings.NewReader(tc.data)); err != tc.err {
+			t.Errorf("%q: got %v, want %v", tc.data, err, tc.err)
+		}
+	}
+}
+
+func TestDecodeXBMForgedSize(t *testing.T) {
+	data := "#define a_width 65535\n#define a_height 65535\nstatic char a_bits[] = {0x00};"
+	n := allocated(func() {
+		if _, err := DecodeXBM(strings.NewReader(data)); err != ErrLength {
+			t.Errorf("got %v, want ErrLength", err)
+		}
+	})
+	if n > 1<<20 {
+		t.Errorf("%d bytes allocated for truncated data", n)
+	}
+}
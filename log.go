@@ -0,0 +1,47 @@
+package epaper
+
+import (
+	"time"
+)
+
+// Logger receives leveled events from the device, it is implemented by *slog.Logger.
+// Args are alternating keys and values.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// WithLogger sets logger receiving events about init, LUT loads, window writes, busy waits and errors
+func WithLogger(l Logger) Option {
+	return func(d *Device) {
+		d.log = l
+	}
+}
+
+// nopLogger discards everything
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...interface{}) {}
+func (nopLogger) Info(msg string, args ...interface{})  {}
+func (nopLogger) Warn(msg string, args ...interface{})  {}
+func (nopLogger) Error(msg string, args ...interface{}) {}
+
+// logs reports whether events reach any logger,
+// hot paths check it so that boxing of the arguments does not allocate for nothing
+func (d *Device) logs() bool {
+	_, nop := d.log.(nopLogger)
+	return !nop
+}
+
+// logBusy logs how long the device was busy
+func (d *Device) logBusy(start time.Time, err error) {
+	if err != nil {
+		d.log.Error("epaper: busy wait failed", "err", err)
+		return
+	}
+	if d.logs() {
+		d.log.Debug("epaper: busy wait", "duration", time.Since(start))
+	}
+}
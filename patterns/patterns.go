@@ -0,0 +1,142 @@
+/*
+Test patterns for panel calibration,
+each pattern is image.Mono of the size of the display
+*/
+package patterns
+
+import (
+	"image"
+	"image/color"
+	"strconv"
+
+	"github.com/drahoslove/epaper"
+	eimage "github.com/drahoslove/epaper/image"
+)
+
+var (
+	black = color.Black
+	white = color.White
+)
+
+// Blank returns white image of the size of the display
+func Blank(dim epaper.Dim) eimage.Mono {
+	m := eimage.NewMono(image.Rect(0, 0, int(dim.WIDTH), int(dim.HEIGHT)))
+	m.Clear(white)
+	return m
+}
+
+// Checkerboard returns checkerboard with squares of given size in pixels,
+// top left square is black, pitch below 1 is treated as 1
+func Checkerboard(dim epaper.Dim, pitch int) eimage.Mono {
+	m := Blank(dim)
+	checkerboard(&m, m.Bounds(), atLeastOne(pitch))
+	return m
+}
+
+// Grid returns 1px wide black lines every spacing pixels, starting at 0,
+// spacing below 1 is treated as 1
+func Grid(dim epaper.Dim, spacing int) eimage.Mono {
+	m := Blank(dim)
+	spacing = atLeastOne(spacing)
+	w, h := int(dim.WIDTH), int(dim.HEIGHT)
+	for x := 0; x < w; x += spacing {
+		m.DrawVerticalLine(black, image.Pt(x, 0), h-1)
+	}
+	for y := 0; y < h; y += spacing {
+		m.DrawHorizontalLine(black, image.Pt(0, y), w-1)
+	}
+	return m
+}
+
+// Gradient returns dithered gradient from black at the top to white at the bottom
+func Gradient(dim epaper.Dim) eimage.Mono {
+	m := Blank(dim)
+	gradient(&m, m.Bounds())
+	return m
+}
+
+// Card returns test card combining edge markers, coordinate labels,
+// orientation arrow pointing to the top, checkerboards and gradient
+func Card(dim epaper.Dim) eimage.Mono {
+	m := Blank(dim)
+	w, h := int(dim.WIDTH), int(dim.HEIGHT)
+
+	edgeMarkers(&m)
+
+	// orientation arrow
+	top := image.Pt(w/2, 8)
+	m.DrawLine(black, top, top.Add(image.Pt(0, 30)))
+	m.DrawLine(black, top, top.Add(image.Pt(-8, 8)))
+	m.DrawLine(black, top, top.Add(image.Pt(8, 8)))
+	m.DrawString(black, "TOP", 10, top.Add(image.Pt(4, 28)))
+
+	// coordinate labels
+	m.DrawString(black, "0,0", 10, image.Pt(6, 16))
+	last := strconv.Itoa(w-1) + "," + strconv.Itoa(h-1)
+	m.DrawString(black, last, 10, image.Pt(w-6-6*len(last), h-8))
+
+	// checkerboards of growing pitch next to each other
+	band := image.Rect(6, h/4, w-6, h/2)
+	for i, pitch := range []int{1, 2, 4, 8} {
+		x0 := band.Min.X + band.Dx()*i/4
+		x1 := band.Min.X + band.Dx()*(i+1)/4
+		checkerboard(&m, image.Rect(x0, band.Min.Y, x1, band.Max.Y), pitch)
+	}
+
+	gradient(&m, image.Rect(6, h/2+6, w-6, h*3/4))
+	return m
+}
+
+func atLeastOne(n int) int {
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+func checkerboard(m *eimage.Mono, r image.Rectangle, pitch int) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if ((x-r.Min.X)/pitch+(y-r.Min.Y)/pitch)%2 == 0 {
+				m.Set(x, y, black)
+			} else {
+				m.Set(x, y, white)
+			}
+		}
+	}
+}
+
+var bayer = eimage.Bayer(4)
+
+func gradient(m *eimage.Mono, r image.Rectangle) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		level := float64(17*(y-r.Min.Y)/r.Dy()) / 16 // 17 levels from 0 to 1
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if level > bayer.At(x, y) {
+				m.Set(x, y, white)
+			} else {
+				m.Set(x, y, black)
+			}
+		}
+	}
+}
+
+// edgeMarkers draws outline with ticks every 10 pixels, longer every 50 pixels
+func edgeMarkers(m *eimage.Mono) {
+	w, h := int(m.Width()), int(m.Height())
+	m.StrokeRect(black, image.Rect(0, 0, w-1, h-1))
+	tick := func(i int) int {
+		if i%50 == 0 {
+			return 5
+		}
+		return 2
+	}
+	for x := 10; x < w; x += 10 {
+		m.DrawVerticalLine(black, image.Pt(x, 0), tick(x))
+		m.DrawVerticalLine(black, image.Pt(x, h-1-tick(x)), tick(x))
+	}
+	for y := 10; y < h; y += 10 {
+		m.DrawHorizontalLine(black, image.Pt(0, y), tick(y))
+		m.DrawHorizontalLine(black, image.Pt(w-1-tick(y), y), tick(y))
+	}
+}
@@ -0,0 +1,87 @@
+package patterns
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+
+	"github.com/drahoslove/epaper"
+)
+
+var dim = epaper.Dim{WIDTH: 128, HEIGHT: 296}
+
+func TestSize(t *testing.T) {
+	for name, m := range map[string]interface {
+		Width() uint
+		Height() uint
+	}{
+		"blank":        Blank(dim),
+		"checkerboard": Checkerboard(dim, 3),
+		"grid":         Grid(dim, 10),
+		"gradient":     Gradient(dim),
+		"card":         Card(dim),
+	} {
+		if m.Width() != dim.WIDTH || m.Height() != dim.HEIGHT {
+			t.Errorf("%s is %dx%d", name, m.Width(), m.Height())
+		}
+	}
+}
+
+func TestCheckerboard(t *testing.T) {
+	m := Checkerboard(dim, 2)
+	for _, p := range []struct {
+		x, y int
+		c    color.Color
+	}{
+		{0, 0, color.Black}, {1, 1, color.Black}, {2, 0, color.White},
+		{0, 2, color.White}, {2, 2, color.Black}, {127, 295, color.Black},
+	} {
+		if m.At(p.x, p.y) != p.c {
+			t.Errorf("pixel %d,%d is %v", p.x, p.y, m.At(p.x, p.y))
+		}
+	}
+}
+
+func TestGrid(t *testing.T) {
+	m := Grid(dim, 10)
+	for _, p := range []struct {
+		x, y int
+		c    color.Color
+	}{
+		{0, 5, color.Black}, {10, 7, color.Black}, {5, 290, color.Black},
+		{5, 5, color.White}, {127, 295, color.White},
+	} {
+		if m.At(p.x, p.y) != p.c {
+			t.Errorf("pixel %d,%d is %v", p.x, p.y, m.At(p.x, p.y))
+		}
+	}
+}
+
+func TestInvalidPitch(t *testing.T) {
+	for _, n := range []int{0, -3} {
+		if !bytes.Equal(Checkerboard(dim, n), Checkerboard(dim, 1)) {
+			t.Errorf("checkerboard with pitch %d differs from pitch 1", n)
+		}
+		if !bytes.Equal(Grid(dim, n), Grid(dim, 1)) {
+			t.Errorf("grid with spacing %d differs from spacing 1", n)
+		}
+	}
+}
+
+func TestGradient(t *testing.T) {
+	m := Gradient(dim)
+	count := func(y int) (n int) {
+		for x := 0; x < int(dim.WIDTH); x++ {
+			if m.At(x, y) == color.White {
+				n++
+			}
+		}
+		return n
+	}
+	if count(0) != 0 || count(int(dim.HEIGHT)-1) != int(dim.WIDTH) {
+		t.Error("gradient should go from black to white")
+	}
+	if n := count(int(dim.HEIGHT) / 2); n < 50 || n > 78 {
+		t.Errorf("%d white pixels in the middle row", n)
+	}
+}
@@ -0,0 +1,159 @@
+package epaper
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// frameSaveInterval limits how often the frame file is rewritten
+const frameSaveInterval = 10 * time.Second
+
+// frameSaver keeps the last displayed frame until it is written to the frame file,
+// it has own lock so the file is written without holding the device
+type frameSaver struct {
+	mu      sync.Mutex
+	shown   []byte // raw Mono data of the last displayed frame
+	pending bool   // shown is newer than the file
+	saved   time.Time
+	timer   *time.Timer // scheduled save, nil if none
+
+	saving sync.Mutex // keeps writes of the file in order, held without mu
+}
+
+// WithFrameFile keeps copy of the last displayed frame in the file.
+//
+// The frame is loaded when the device is used for the first time,
+// so partial update can be done right after restart,
+// without the need to clear the display by full update first.
+// Each panel should use its own file.
+//
+// Only frames of successful refreshes are saved, at most once per 10 seconds
+// (the last one is saved when the interval ends) and on Close.
+// Failed writes are logged, they do not fail the display.
+func WithFrameFile(path string) Option {
+	return func(d *Device) {
+		d.frameFile = path
+	}
+}
+
+// loadFrame reads frame stored by saveFrame,
+// file is ignored if it does not match dimension of the display
+func (d *Device) loadFrame(frame []byte) bool {
+	if d.frameFile == "" {
+		return false
+	}
+	data, err := ioutil.ReadFile(d.frameFile)
+	if err != nil || len(data) != 4+len(frame) {
+		return false
+	}
+	width := uint(data[0])<<8 | uint(data[1])
+	height := uint(data[2])<<8 | uint(data[3])
+	if width != d.WIDTH || height != d.HEIGHT {
+		return false
+	}
+	copy(frame, data[4:])
+	return true
+}
+
+// frameShown records the framebuffer as displayed and schedules saving it.
+//
+// It is called by the refresh goroutine before the refresh is reported done,
+// nothing modifies the framebuffer until then, as writes wait for the refresh.
+func (d *Device) frameShown() {
+	if d.frameFile == "" {
+		return
+	}
+	s := &d.frames
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.shown) != 4+len(d.frame) {
+		s.shown = make([]byte, 4+len(d.frame))
+		s.shown[0], s.shown[1] = byte(d.WIDTH>>8), byte(d.WIDTH)
+		s.shown[2], s.shown[3] = byte(d.HEIGHT>>8), byte(d.HEIGHT)
+	}
+	copy(s.shown[4:], d.frame)
+	s.pending = true
+	if s.timer == nil {
+		wait := frameSaveInterval - time.Since(s.saved)
+		if wait < 0 {
+			wait = 0
+		}
+		s.timer = time.AfterFunc(wait, func() { d.saveFrame() })
+	}
+}
+
+// saveFrame atomically replaces the frame file with the last displayed frame,
+// as raw Mono data: 4-byte size header followed by bitmap, without the MONO file header
+func (d *Device) saveFrame() error {
+	s := &d.frames
+	s.saving.Lock()
+	defer s.saving.Unlock()
+	s.mu.Lock()
+	s.timer = nil
+	if !s.pending {
+		s.mu.Unlock()
+		return nil
+	}
+	data := append([]byte(nil), s.shown...)
+	s.pending = false
+	s.saved = time.Now()
+	s.mu.Unlock()
+
+	err := writeFileAtomic(d.frameFile, data)
+	if err != nil {
+		d.log.Error("epaper: saving frame failed", "err", err)
+	}
+	return err
+}
+
+// flushFrame saves the frame right away instead of waiting for the scheduled save
+func (d *Device) flushFrame() {
+	s := &d.frames
+	s.mu.Lock()
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.mu.Unlock()
+	d.saveFrame()
+}
+
+// writeFileAtomic writes data to temporary file and renames it,
+// so the file is either old or new one even if the process is killed or power is lost
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := ioutil.TempFile(dir, filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync() // data must be on disk before the rename is
+	}
+	if e := tmp.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir makes rename within the directory durable
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = f.Sync()
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	return err
+}
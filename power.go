@@ -0,0 +1,104 @@
+package epaper
+
+import (
+	"time"
+)
+
+// State is power state of the Device
+type State int
+
+const (
+	StateOff         State = iota // not initialized yet
+	StateInitialized              // initialized, but nothing displayed since
+	StateIdle                     // waiting for next frame
+	StateBusy                     // refresh in progress
+	StateSleeping                 // in deep sleep, needs reset to wake up
+)
+
+func (s State) String() string {
+	switch s {
+	case StateOff:
+		return "off"
+	case StateInitialized:
+		return "initialized"
+	case StateIdle:
+		return "idle"
+	case StateBusy:
+		return "busy"
+	case StateSleeping:
+		return "sleeping"
+	}
+	return "unknown"
+}
+
+// WithAutoSleep puts the device into deep sleep after it was idle for given duration.
+// Zero duration disables auto sleep.
+func WithAutoSleep(idle time.Duration) Option {
+	return func(d *Device) {
+		d.idleTimeout = idle
+	}
+}
+
+// State returns current power state of the device
+func (d *Device) State() State {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.state
+}
+
+// wake makes sure controller is able to receive data,
+// device in deep sleep (or never initialized) is reset and initialized again
+// using last used update mode
+func (d *Device) wake() {
+	d.stopIdleTimer()
+	d.waitRefresh()
+	if d.state == StateOff || d.state == StateSleeping {
+		d.log.Info("epaper: wake up", "state", d.state)
+		update := d.update
+		if update == "" {
+			update = "full"
+		}
+		d.init(update)
+	}
+}
+
+// idle marks the end of refresh and schedules auto sleep
+func (d *Device) idle() {
+	d.state = StateIdle
+	d.scheduleSleep()
+}
+
+// scheduleSleep (re)starts the auto sleep timer if enabled
+func (d *Device) scheduleSleep() {
+	d.stopIdleTimer()
+	if d.idleTimeout <= 0 {
+		return
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(d.idleTimeout, func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if d.idleTimer != timer { // stopped or rescheduled while waiting for the lock
+			return
+		}
+		d.idleTimer = nil
+		if d.state != StateIdle && d.state != StateInitialized {
+			return
+		}
+		err := d.err // keep error of the last operation for Err
+		d.err = nil
+		d.sleep()
+		if d.err != nil {
+			d.log.Error("epaper: auto sleep failed", "err", d.err)
+		}
+		d.err = err
+	})
+	d.idleTimer = timer
+}
+
+func (d *Device) stopIdleTimer() {
+	if d.idleTimer != nil {
+		d.idleTimer.Stop()
+		d.idleTimer = nil
+	}
+}
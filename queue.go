@@ -0,0 +1,135 @@
+package epaper
+
+import (
+	"errors"
+)
+
+var (
+	ErrBitmapTooSmall = errors.New("epaper: bitmap too small")
+	ErrSuperseded     = errors.New("epaper: frame superseded by newer one")
+	ErrClosed         = errors.New("epaper: device closed")
+)
+
+// Frame is bitmap to be displayed at given position
+type Frame struct {
+	Bitmap        []byte
+	X, Y          int
+	Width, Height uint
+	Update        string // "full" or "partial", empty keeps current update mode
+}
+
+// same reports whether frames target the same region in the same way
+func (f Frame) same(g Frame) bool {
+	return f.X == g.X && f.Y == g.Y &&
+		f.Width == g.Width && f.Height == g.Height &&
+		f.Update == g.Update
+}
+
+type job struct {
+	frame Frame
+	done  chan error
+}
+
+// Submit queues frame to be displayed by the device worker.
+//
+// Bitmap is copied, so the caller may reuse its buffer as soon as Submit returns.
+// Returned channel receives nil once the frame is displayed, or an error.
+// Frame still waiting in the queue is dropped with ErrSuperseded
+// when newer frame for the same region is submitted.
+//
+// Submit is safe for concurrent use.
+func (d *Device) Submit(f Frame) <-chan error {
+	done := make(chan error, 1)
+	if len(f.Bitmap) < int(f.Height*inBytes(f.Width)) {
+		done <- ErrBitmapTooSmall
+		return done
+	}
+	f.Bitmap = append([]byte(nil), f.Bitmap...)
+
+	d.qmu.Lock()
+	defer d.qmu.Unlock()
+	if d.closed {
+		done <- ErrClosed
+		return done
+	}
+	if d.wakeup == nil {
+		d.wakeup = make(chan struct{}, 1)
+		d.stopped = make(chan struct{})
+		go d.work()
+	}
+	queue := d.queue[:0]
+	for _, j := range d.queue {
+		if j.frame.same(f) {
+			j.done <- ErrSuperseded
+			continue
+		}
+		queue = append(queue, j)
+	}
+	d.queue = append(queue, job{f, done})
+	select {
+	case d.wakeup <- struct{}{}:
+	default:
+	}
+	return done
+}
+
+// Close stops the worker, frames still in queue are dropped with ErrClosed.
+// Frame being displayed at the moment is finished first,
+// the last displayed frame and lifetime stats are saved.
+func (d *Device) Close() {
+	d.qmu.Lock()
+	if d.closed {
+		d.qmu.Unlock()
+		return
+	}
+	d.closed = true
+	for _, j := range d.queue {
+		j.done <- ErrClosed
+	}
+	d.queue = nil
+	wakeup, stopped := d.wakeup, d.stopped
+	d.qmu.Unlock()
+
+	if wakeup != nil {
+		close(wakeup)
+		<-stopped
+	}
+	d.mu.Lock()
+	d.waitRefresh()
+	d.stopIdleTimer()
+	d.mu.Unlock()
+	d.flushFrame()
+	if err := d.stats.save(); err != nil {
+		d.log.Error("epaper: saving stats failed", "err", err)
+	}
+}
+
+// work is the only goroutine sending queued frames to the bus
+func (d *Device) work() {
+	defer close(d.stopped)
+	for range d.wakeup {
+		for {
+			d.qmu.Lock()
+			if len(d.queue) == 0 {
+				d.qmu.Unlock()
+				break
+			}
+			j := d.queue[0]
+			d.queue = d.queue[1:]
+			d.qmu.Unlock()
+
+			j.done <- d.displayFrame(j.frame)
+		}
+	}
+}
+
+func (d *Device) displayFrame(f Frame) error {
+	d.lock()
+	defer d.mu.Unlock()
+	if f.Update != "" && f.Update != d.update {
+		d.stopIdleTimer()
+		d.waitRefresh()
+		d.init(f.Update)
+	}
+	return d.display(f.Bitmap, f.X, f.Y, f.Width, f.Height)
+}
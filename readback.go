@@ -0,0 +1,50 @@
+package epaper
+
+import (
+	"errors"
+)
+
+var ErrNotSupported = errors.New("epaper: not supported by the controller or transport")
+
+// ReadRAM reads current content of controller RAM.
+//
+// Returned bitmap is raw image.Mono data (4 bytes of dimension followed by bits),
+// convert it by image.Mono(bitmap) or image.Parse(bitmap).
+// It is not image.Mono itself, as tests of epaper/image import this package
+// and importing epaper/image here would make an import cycle.
+// The copy of the frame kept by the device is replaced by the content read,
+// so partial updates can continue even if the last frame was not known.
+//
+// Both the controller (Cmd.READ_RAM) and the transport (Reader) must support it,
+// otherwise ErrNotSupported is returned.
+func (d *Device) ReadRAM() ([]byte, error) {
+	r, ok := d.transport.(Reader)
+	if !ok || d.Cmd.READ_RAM == 0 {
+		return nil, ErrNotSupported
+	}
+	d.lock()
+	defer d.mu.Unlock()
+	d.wake()
+	defer d.scheduleSleep()
+
+	frame := d.framebuffer()
+	d.setMemoryArea(0, 0, d.WIDTH-1, d.HEIGHT-1)
+	d.setMemoryPointer(0, 0)
+	d.sendCommand(d.Cmd.READ_RAM)
+
+	bitmap := make([]byte, 4+1+len(frame)) // first byte read is dummy
+	if d.err == nil {
+		d.fail(r.ReadData(bitmap[4:]))
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	bitmap = append(bitmap[:4], bitmap[5:]...)
+	bitmap[0], bitmap[1] = byte(d.WIDTH>>8), byte(d.WIDTH)
+	bitmap[2], bitmap[3] = byte(d.HEIGHT>>8), byte(d.HEIGHT)
+
+	copy(frame, bitmap[4:])
+	d.ramStale = false
+	d.log.Debug("epaper: read ram", "bytes", len(frame))
+	return bitmap, nil
+}
@@ -0,0 +1,112 @@
+package epaper
+
+import (
+	"time"
+)
+
+// Refresh is handle of the refresh running on the panel
+type Refresh struct {
+	start time.Time
+	end   time.Time // valid once done is closed
+	err   error     // valid once done is closed
+	done  chan struct{}
+}
+
+// Done returns channel which is closed when the refresh is finished
+func (r *Refresh) Done() <-chan struct{} {
+	return r.done
+}
+
+// Wait blocks until the refresh is finished
+func (r *Refresh) Wait() {
+	<-r.done
+}
+
+// Err returns error which occured while waiting for the refresh to finish
+func (r *Refresh) Err() error {
+	<-r.done
+	return r.err
+}
+
+// Elapsed returns duration of the refresh, or time elapsed so far if it still runs
+func (r *Refresh) Elapsed() time.Duration {
+	select {
+	case <-r.done:
+		return r.end.Sub(r.start)
+	default:
+		return time.Since(r.start)
+	}
+}
+
+// SwapFrameAsync swaps frames like SwapFrame, but does not wait for the refresh to finish.
+//
+// Any following call which needs to write to the controller waits until the refresh is done.
+func (d *Device) SwapFrameAsync() *Refresh {
+	d.lock()
+	defer d.mu.Unlock()
+	d.wake()
+	return d.startRefresh()
+}
+
+// DisplayAsync writes bitmap to RAM like Display, but does not wait for the refresh to finish.
+func (d *Device) DisplayAsync(img []byte, x, y int, imgWidth, imgHeight uint) (*Refresh, error) {
+	d.lock()
+	defer d.mu.Unlock()
+	if err := d.writeFrame(img, x, y, imgWidth, imgHeight); err != nil {
+		return nil, err
+	}
+	return d.startRefresh(), nil
+}
+
+// startRefresh activates display update sequence and watches busy pin in background
+func (d *Device) startRefresh() *Refresh {
+	d.state = StateBusy
+	d.sendCommand(d.Cmd.DISPLAY_UPDATE_CONTROL_2)
+	d.sendData(0xC4)
+	d.sendCommand(d.Cmd.MASTER_ACTIVATION)
+	d.sendCommand(d.Cmd.TERMINATE_FRAME_READ_WRITE)
+
+	r := &Refresh{
+		start: time.Now(),
+		done:  make(chan struct{}),
+	}
+	if d.err != nil { // refresh did not start at all
+		r.err, r.end = d.err, r.start
+		close(r.done)
+		d.state = StateIdle
+		return r
+	}
+	d.refresh = r
+	mode := d.update
+	if mode == "" {
+		mode = "unknown"
+	}
+	go func() {
+		r.err = waitUntilIdle(d.transport)
+		r.end = time.Now()
+		d.logBusy(r.start, r.err)
+		d.stats.refreshed(mode, r.end.Sub(r.start), r.err)
+		if r.err == nil {
+			d.frameShown()
+		}
+		close(r.done)
+
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if d.refresh == r { // nobody waited for it yet
+			d.refresh = nil
+			d.idle()
+		}
+	}()
+	return r
+}
+
+// waitRefresh blocks until running refresh (if any) is finished
+func (d *Device) waitRefresh() {
+	if d.refresh == nil {
+		return
+	}
+	<-d.refresh.done
+	d.refresh = nil
+	d.state = StateIdle
+}
@@ -7,6 +7,7 @@ type Module struct {
 	Dim
 	Lut
 	Cmd
+	SPI
 }
 
 type Ink struct {
@@ -24,6 +25,11 @@ type Lut struct {
 	PARTIAL []byte
 }
 
+type SPI struct {
+	SPEED uint32 // maximal clock frequency in Hz
+	MODE  uint8  // clock polarity (bit 1) and phase (bit 0)
+}
+
 type Cmd struct {
 	DRIVER_OUTPUT_CONTROL                byte
 	BOOSTER_SOFT_START_CONTROL           byte
@@ -46,4 +52,5 @@ type Cmd struct {
 	SET_RAM_X_ADDRESS_COUNTER            byte
 	SET_RAM_Y_ADDRESS_COUNTER            byte
 	TERMINATE_FRAME_READ_WRITE           byte
+	READ_RAM                             byte // 0 if controller can not read RAM
 }
@@ -0,0 +1,18 @@
+package spidev
+
+import (
+	"unsafe"
+)
+
+// file is opened device, replaced by fake in tests
+type file interface {
+	ioctl(req uintptr, arg unsafe.Pointer) error
+	Read(p []byte) (int, error)
+	Write(p []byte) (int, error)
+	Close() error
+}
+
+var (
+	openFile = openDevice // opens device file by path
+	fdFile   = fdDevice   // wraps file descriptor returned by ioctl
+)
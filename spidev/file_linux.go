@@ -0,0 +1,31 @@
+package spidev
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+type device struct {
+	*os.File
+}
+
+func (d device) ioctl(req uintptr, arg unsafe.Pointer) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, d.Fd(), req, uintptr(arg))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func openDevice(path string) (file, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	return device{f}, nil
+}
+
+func fdDevice(fd uintptr, name string) file {
+	return device{os.NewFile(fd, name)}
+}
@@ -0,0 +1,37 @@
+//go:build !linux
+// +build !linux
+
+package spidev
+
+import (
+	"errors"
+	"unsafe"
+)
+
+var errNotSupported = errors.New("only supported on linux")
+
+type device struct{}
+
+func (device) ioctl(req uintptr, arg unsafe.Pointer) error {
+	return errNotSupported
+}
+
+func (device) Read(p []byte) (int, error) {
+	return 0, errNotSupported
+}
+
+func (device) Write(p []byte) (int, error) {
+	return 0, errNotSupported
+}
+
+func (device) Close() error {
+	return errNotSupported
+}
+
+func openDevice(path string) (file, error) {
+	return nil, errNotSupported
+}
+
+func fdDevice(fd uintptr, name string) file {
+	return device{}
+}
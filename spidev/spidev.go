@@ -0,0 +1,281 @@
+/*
+Transport for generic Linux boards,
+uses spidev (/dev/spidevX.Y) for SPI and GPIO character device (/dev/gpiochipN) for pins
+*/
+package spidev
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// Config describes where the display is connected.
+//
+// SPI clock and mode are not part of it, epaper.Device sets them on Init
+// from Module.SPI, or from epaper.WithSPI which takes precedence.
+type Config struct {
+	SPI   string // spidev device, eg. /dev/spidev0.0
+	GPIO  string // gpio chip device, eg. /dev/gpiochip0
+	DC    uint32 // line offsets on gpio chip
+	Reset uint32
+	Busy  uint32
+}
+
+// DefaultConfig matches the wiring used with Raspberry Pi
+var DefaultConfig = Config{
+	SPI:   "/dev/spidev0.0",
+	GPIO:  "/dev/gpiochip0",
+	DC:    25,
+	Reset: 22,
+	Busy:  24,
+}
+
+// SPI clock used from Open until the device configures its own
+const initialSpeed = 2000000
+
+// maximal length of single SPI transfer, default bufsiz of spidev kernel module
+const defaultMaxTransfer = 4096
+
+// parameter of spidev kernel module holding its actual bufsiz
+var bufsizPath = "/sys/module/spidev/parameters/bufsiz"
+
+// ioctl request numbers from linux/spi/spidev.h and linux/gpio.h
+const (
+	spiIocWrMode            = 0x40016b01
+	spiIocWrBitsPerWord     = 0x40016b03
+	spiIocWrMaxSpeedHz      = 0x40046b04
+	gpioGetLineHandle       = 0xc16cb403
+	gpioHandleGetLineValues = 0xc040b408
+	gpioHandleSetLineValues = 0xc040b409
+)
+
+// SPI mode flag for bidirectional data line
+const spi3Wire = 0x10
+
+// gpiohandle_request flags
+const (
+	gpioHandleRequestInput        = 1 << 0
+	gpioHandleRequestOutput       = 1 << 1
+	gpioHandleRequestBiasPullDown = 1 << 6
+)
+
+// struct gpiohandle_request
+type gpioHandleRequest struct {
+	lineOffsets   [64]uint32
+	flags         uint32
+	defaultValues [64]uint8
+	consumerLabel [32]byte
+	lines         uint32
+	fd            int32
+}
+
+// struct gpiohandle_data
+type gpioHandleData struct {
+	values [64]uint8
+}
+
+// Transport implements epaper.Transport
+type Transport struct {
+	config Config
+	max    int // maximal length of single transfer
+	spi    file
+	out    file // DC and RESET lines
+	in     file // BUSY line
+	dc     uint8
+	rst    uint8
+	mode   uint8 // SPI mode set by ConfigureSPI
+}
+
+// Open opens SPI and GPIO devices given by config
+func Open(config Config) (*Transport, error) {
+	t := &Transport{config: config, rst: 1, max: defaultMaxTransfer}
+	var err error
+
+	if b, err := ioutil.ReadFile(bufsizPath); err == nil {
+		if n, err := strconv.Atoi(strings.TrimSpace(string(b))); err == nil && n > 0 {
+			t.max = n
+		}
+	}
+
+	t.spi, err = openFile(config.SPI)
+	if err != nil {
+		return nil, fmt.Errorf("spidev: %w", err)
+	}
+	bits := uint8(8)
+	if err = t.spi.ioctl(spiIocWrBitsPerWord, unsafe.Pointer(&bits)); err != nil {
+		t.Close()
+		return nil, fmt.Errorf("spidev: set bits per word: %w", err)
+	}
+	if err = t.ConfigureSPI(initialSpeed, 0); err != nil {
+		t.Close()
+		return nil, err
+	}
+
+	chip, err := openFile(config.GPIO)
+	if err != nil {
+		t.Close()
+		return nil, fmt.Errorf("spidev: %w", err)
+	}
+	defer chip.Close()
+
+	t.out, err = requestLines(chip, gpioHandleRequestOutput, []uint32{config.DC, config.Reset}, []uint8{t.dc, t.rst})
+	if err == nil {
+		t.in, err = requestLines(chip, gpioHandleRequestInput|gpioHandleRequestBiasPullDown, []uint32{config.Busy}, nil)
+	}
+	if err != nil {
+		t.Close()
+		return nil, fmt.Errorf("spidev: request lines on %s: %w", config.GPIO, err)
+	}
+	return t, nil
+}
+
+func requestLines(chip file, flags uint32, lines []uint32, values []uint8) (file, error) {
+	req := gpioHandleRequest{
+		flags: flags,
+		lines: uint32(len(lines)),
+	}
+	copy(req.lineOffsets[:], lines)
+	copy(req.defaultValues[:], values)
+	copy(req.consumerLabel[:], "epaper")
+	if err := chip.ioctl(gpioGetLineHandle, unsafe.Pointer(&req)); err != nil {
+		return nil, err
+	}
+	return fdFile(uintptr(req.fd), "gpio-line-handle"), nil
+}
+
+// Close releases all devices
+func (t *Transport) Close() error {
+	var err error
+	for _, f := range []file{t.in, t.out, t.spi} {
+		if f != nil {
+			if e := f.Close(); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	t.in, t.out, t.spi = nil, nil, nil
+	return err
+}
+
+func (t *Transport) setOutputs(dc, rst uint8) error {
+	if dc == t.dc && rst == t.rst {
+		return nil
+	}
+	data := gpioHandleData{}
+	data.values[0], data.values[1] = dc, rst
+	if err := t.out.ioctl(gpioHandleSetLineValues, unsafe.Pointer(&data)); err != nil {
+		return fmt.Errorf("spidev: set lines: %w", err)
+	}
+	t.dc, t.rst = dc, rst
+	return nil
+}
+
+func (t *Transport) transfer(data []byte) error {
+	for len(data) > 0 {
+		n := len(data)
+		if n > t.max {
+			n = t.max
+		}
+		// write is half duplex transfer using speed and mode set in Open
+		if _, err := t.spi.Write(data[:n]); err != nil {
+			return fmt.Errorf("spidev: transfer: %w", err)
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// ConfigureSPI sets SPI clock frequency in Hz and mode,
+// zero speed keeps the current clock
+func (t *Transport) ConfigureSPI(speed uint32, mode uint8) error {
+	if err := t.spi.ioctl(spiIocWrMode, unsafe.Pointer(&mode)); err != nil {
+		return fmt.Errorf("spidev: set mode: %w", err)
+	}
+	t.mode = mode
+	if speed == 0 {
+		return nil
+	}
+	if err := t.spi.ioctl(spiIocWrMaxSpeedHz, unsafe.Pointer(&speed)); err != nil {
+		return fmt.Errorf("spidev: set speed: %w", err)
+	}
+	return nil
+}
+
+// MaxTransfer returns maximal length of data sent at once,
+// longer data are split by Data anyway
+func (t *Transport) MaxTransfer() int {
+	return t.max
+}
+
+// Command sends command byte
+func (t *Transport) Command(cmd byte) error {
+	if err := t.setOutputs(0, t.rst); err != nil {
+		return err
+	}
+	return t.transfer([]byte{cmd})
+}
+
+// Data sends data bytes
+func (t *Transport) Data(data []byte) error {
+	if err := t.setOutputs(1, t.rst); err != nil {
+		return err
+	}
+	return t.transfer(data)
+}
+
+// ReadData reads data from the controller,
+// data line is switched to bidirectional (3-wire) mode for the time of reading
+func (t *Transport) ReadData(data []byte) error {
+	if err := t.setOutputs(1, t.rst); err != nil {
+		return err
+	}
+	mode := t.mode | spi3Wire
+	if err := t.spi.ioctl(spiIocWrMode, unsafe.Pointer(&mode)); err != nil {
+		return fmt.Errorf("spidev: set mode: %w", err)
+	}
+	var err error
+	for len(data) > 0 && err == nil {
+		n := len(data)
+		if n > t.max {
+			n = t.max
+		}
+		if _, err = io.ReadFull(t.spi, data[:n]); err != nil {
+			err = fmt.Errorf("spidev: read: %w", err)
+		}
+		data = data[n:]
+	}
+	mode = t.mode
+	if e := t.spi.ioctl(spiIocWrMode, unsafe.Pointer(&mode)); e != nil && err == nil {
+		err = fmt.Errorf("spidev: set mode: %w", e)
+	}
+	return err
+}
+
+// Busy reads the BUSY line
+func (t *Transport) Busy() (bool, error) {
+	data := gpioHandleData{}
+	if err := t.in.ioctl(gpioHandleGetLineValues, unsafe.Pointer(&data)); err != nil {
+		return false, fmt.Errorf("spidev: get lines: %w", err)
+	}
+	return data.values[0] == 1, nil // doc say Low == busy, but it is the oposite
+}
+
+// Reset pulls RESET line low for a while
+func (t *Transport) Reset() error {
+	if err := t.setOutputs(t.dc, 0); err != nil {
+		return err
+	}
+	time.Sleep(resetDelay)
+	if err := t.setOutputs(t.dc, 1); err != nil {
+		return err
+	}
+	time.Sleep(resetDelay)
+	return nil
+}
+
+var resetDelay = time.Millisecond * 100
@@ -0,0 +1,303 @@
+package spidev
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"unsafe"
+
+	"github.com/drahoslove/epaper"
+)
+
+var (
+	_ epaper.Transport     = (*Transport)(nil)
+	_ epaper.MaxTransferer = (*Transport)(nil)
+	_ epaper.SPIConfigurer = (*Transport)(nil)
+	_ epaper.Reader        = (*Transport)(nil)
+)
+
+// fakeBus emulates spidev and gpiochip devices
+type fakeBus struct {
+	mode   uint8
+	bits   uint8
+	speed  uint32
+	lines  map[uint32]uint8
+	flags  map[uint32]uint32
+	writes []fakeWrite
+	reads  int     // bytes read
+	modes  []uint8 // modes in which reads happened
+	open   map[*fakeFile]bool
+	fds    map[uintptr]*fakeFile
+}
+
+type fakeWrite struct {
+	dc   uint8
+	data []byte
+}
+
+type fakeFile struct {
+	bus   *fakeBus
+	path  string
+	lines []uint32 // lines of line handle
+}
+
+func newFakeBus() *fakeBus {
+	return &fakeBus{
+		lines: map[uint32]uint8{},
+		flags: map[uint32]uint32{},
+		open:  map[*fakeFile]bool{},
+		fds:   map[uintptr]*fakeFile{},
+	}
+}
+
+// install replaces device file layer with the fake one for the duration of the test
+func (b *fakeBus) install(t *testing.T) {
+	open, fd, delay, bufsiz := openFile, fdFile, resetDelay, bufsizPath
+	t.Cleanup(func() {
+		openFile, fdFile, resetDelay, bufsizPath = open, fd, delay, bufsiz
+	})
+	resetDelay = 0
+	bufsizPath = ""
+	openFile = func(path string) (file, error) {
+		if path != DefaultConfig.SPI && path != DefaultConfig.GPIO {
+			return nil, errors.New("no such device")
+		}
+		f := &fakeFile{bus: b, path: path}
+		b.open[f] = true
+		return f, nil
+	}
+	fdFile = func(fd uintptr, name string) file {
+		return b.fds[fd]
+	}
+}
+
+func (f *fakeFile) ioctl(req uintptr, arg unsafe.Pointer) error {
+	b := f.bus
+	switch req {
+	case spiIocWrMode:
+		b.mode = *(*uint8)(arg)
+	case spiIocWrBitsPerWord:
+		b.bits = *(*uint8)(arg)
+	case spiIocWrMaxSpeedHz:
+		b.speed = *(*uint32)(arg)
+	case gpioGetLineHandle:
+		req := (*gpioHandleRequest)(arg)
+		h := &fakeFile{bus: b, path: "handle"}
+		for i := uint32(0); i < req.lines; i++ {
+			line := req.lineOffsets[i]
+			h.lines = append(h.lines, line)
+			b.flags[line] = req.flags
+			if req.flags&gpioHandleRequestOutput != 0 {
+				b.lines[line] = req.defaultValues[i]
+			}
+		}
+		fd := uintptr(len(b.fds) + 100)
+		b.fds[fd] = h
+		b.open[h] = true
+		req.fd = int32(fd)
+	case gpioHandleSetLineValues:
+		data := (*gpioHandleData)(arg)
+		for i, line := range f.lines {
+			b.lines[line] = data.values[i]
+		}
+	case gpioHandleGetLineValues:
+		data := (*gpioHandleData)(arg)
+		for i, line := range f.lines {
+			data.values[i] = b.lines[line]
+		}
+	default:
+		return errors.New("unknown ioctl")
+	}
+	return nil
+}
+
+func (f *fakeFile) Write(p []byte) (int, error) {
+	if len(p) > defaultMaxTransfer {
+		return 0, errors.New("message too long")
+	}
+	b := f.bus
+	b.writes = append(b.writes, fakeWrite{
+		dc:   b.lines[DefaultConfig.DC],
+		data: append([]byte{}, p...),
+	})
+	return len(p), nil
+}
+
+// Read returns bytes counting from 0
+func (f *fakeFile) Read(p []byte) (int, error) {
+	b := f.bus
+	if len(p) > defaultMaxTransfer {
+		return 0, errors.New("message too long")
+	}
+	for i := range p {
+		p[i] = byte(b.reads)
+		b.reads++
+	}
+	b.modes = append(b.modes, b.mode)
+	return len(p), nil
+}
+
+func (f *fakeFile) Close() error {
+	delete(f.bus.open, f)
+	return nil
+}
+
+func TestOpen(t *testing.T) {
+	bus := newFakeBus()
+	bus.install(t)
+
+	tr, err := Open(DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bus.mode != 0 || bus.bits != 8 || bus.speed != initialSpeed {
+		t.Errorf("spi configured as mode %d, %d bits, %d Hz", bus.mode, bus.bits, bus.speed)
+	}
+	if bus.flags[DefaultConfig.DC]&gpioHandleRequestOutput == 0 ||
+		bus.flags[DefaultConfig.Reset]&gpioHandleRequestOutput == 0 {
+		t.Error("DC and RESET lines should be outputs")
+	}
+	if bus.flags[DefaultConfig.Busy]&gpioHandleRequestInput == 0 {
+		t.Error("BUSY line should be input")
+	}
+	if bus.lines[DefaultConfig.Reset] != 1 {
+		t.Error("RESET line should be high by default")
+	}
+	if len(bus.open) != 3 { // gpio chip is not needed after lines are requested
+		t.Errorf("%d files open, expected 3", len(bus.open))
+	}
+
+	if err := tr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(bus.open) != 0 {
+		t.Errorf("%d files left open", len(bus.open))
+	}
+}
+
+func TestOpenMissingDevice(t *testing.T) {
+	bus := newFakeBus()
+	bus.install(t)
+
+	config := DefaultConfig
+	config.GPIO = "/dev/gpiochip9"
+	if _, err := Open(config); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(bus.open) != 0 {
+		t.Errorf("%d files left open", len(bus.open))
+	}
+}
+
+func TestCommandAndData(t *testing.T) {
+	bus := newFakeBus()
+	bus.install(t)
+	tr, err := Open(DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tr.Close()
+
+	frame := bytes.Repeat([]byte{0xAA}, tr.MaxTransfer()+10)
+	if err := tr.Command(0x24); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Data(frame); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bus.writes) != 3 {
+		t.Fatalf("%d writes, expected 3", len(bus.writes))
+	}
+	if w := bus.writes[0]; w.dc != 0 || !bytes.Equal(w.data, []byte{0x24}) {
+		t.Errorf("command written as %+v", w)
+	}
+	data := []byte{}
+	for _, w := range bus.writes[1:] {
+		if w.dc != 1 {
+			t.Error("data written with DC low")
+		}
+		data = append(data, w.data...)
+	}
+	if !bytes.Equal(data, frame) {
+		t.Error("data differ")
+	}
+}
+
+func TestBusyAndReset(t *testing.T) {
+	bus := newFakeBus()
+	bus.install(t)
+	tr, err := Open(DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tr.Close()
+
+	for _, level := range []uint8{1, 0} {
+		bus.lines[DefaultConfig.Busy] = level
+		busy, err := tr.Busy()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if busy != (level == 1) {
+			t.Errorf("busy is %v for level %d", busy, level)
+		}
+	}
+
+	if err := tr.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if bus.lines[DefaultConfig.Reset] != 1 {
+		t.Error("RESET line should be high after reset")
+	}
+}
+
+func TestConfigureSPI(t *testing.T) {
+	bus := newFakeBus()
+	bus.install(t)
+	tr, err := Open(DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tr.Close()
+
+	if err := tr.ConfigureSPI(10000000, 3); err != nil {
+		t.Fatal(err)
+	}
+	if bus.speed != 10000000 || bus.mode != 3 {
+		t.Errorf("spi configured as mode %d, %d Hz", bus.mode, bus.speed)
+	}
+
+	if err := tr.ConfigureSPI(0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if bus.speed != 10000000 || bus.mode != 1 {
+		t.Errorf("mode alone configured as mode %d, %d Hz", bus.mode, bus.speed)
+	}
+}
+
+func TestReadData(t *testing.T) {
+	bus := newFakeBus()
+	bus.install(t)
+	tr, err := Open(DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tr.Close()
+
+	data := make([]byte, tr.MaxTransfer()+10)
+	if err := tr.ReadData(data); err != nil {
+		t.Fatal(err)
+	}
+	for i := range data {
+		if data[i] != byte(i) {
+			t.Fatalf("byte %d read as %d", i, data[i])
+		}
+	}
+	if len(bus.modes) != 2 || bus.modes[0]&spi3Wire == 0 {
+		t.Errorf("read in modes %v, expected 2 reads in 3-wire mode", bus.modes)
+	}
+	if bus.mode != 0 {
+		t.Error("mode is not restored after reading")
+	}
+}
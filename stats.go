@@ -0,0 +1,189 @@
+package epaper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Stats is snapshot of device telemetry
+type Stats struct {
+	Refreshes         map[string]uint64        // number of refreshes by update mode since start
+	LifetimeRefreshes map[string]uint64        // including previous runs, see WithStatsFile
+	BusyTime          map[string]time.Duration // total duration of refreshes by update mode
+	LastBusy          time.Duration            // duration of the last refresh
+	BytesSent         uint64                   // commands and data
+	Errors            uint64
+}
+
+// statsSaveInterval limits how often the stats file is rewritten
+const statsSaveInterval = time.Minute
+
+// stats collects telemetry, it has own lock so it can be read while the device is busy
+type stats struct {
+	mu       sync.Mutex
+	file     string
+	loaded   bool
+	current  Stats
+	lifetime map[string]uint64 // loaded from file
+	dirty    bool              // lifetime changed since it was saved
+	saved    time.Time
+
+	saving sync.Mutex // keeps writes of the file in order, held without mu
+}
+
+// WithStatsFile keeps lifetime refresh counts in the file,
+// it is rewritten at most once a minute and on Close
+func WithStatsFile(path string) Option {
+	return func(d *Device) {
+		d.stats.file = path
+	}
+}
+
+// Stats returns snapshot of the device telemetry
+func (d *Device) Stats() Stats {
+	return d.stats.snapshot()
+}
+
+func (s *stats) sent(n int) {
+	s.mu.Lock()
+	s.current.BytesSent += uint64(n)
+	s.mu.Unlock()
+}
+
+func (s *stats) failed() {
+	s.mu.Lock()
+	s.current.Errors++
+	s.mu.Unlock()
+}
+
+// refreshed records finished refresh and saves the stats file when it is due
+func (s *stats) refreshed(mode string, busy time.Duration, err error) {
+	s.mu.Lock()
+	if err != nil {
+		s.current.Errors++
+		s.mu.Unlock()
+		return
+	}
+	s.load()
+	if s.current.Refreshes == nil {
+		s.current.Refreshes = map[string]uint64{}
+		s.current.BusyTime = map[string]time.Duration{}
+	}
+	s.current.Refreshes[mode]++
+	s.current.BusyTime[mode] += busy
+	s.current.LastBusy = busy
+	s.lifetime[mode]++
+	s.dirty = true
+	due := time.Since(s.saved) >= statsSaveInterval
+	s.mu.Unlock()
+	if due {
+		s.save()
+	}
+}
+
+// save writes lifetime counts to the stats file if they changed since the last save
+func (s *stats) save() error {
+	s.saving.Lock()
+	defer s.saving.Unlock()
+	s.mu.Lock()
+	if !s.dirty || s.file == "" {
+		s.mu.Unlock()
+		return nil
+	}
+	data, _ := json.Marshal(s.lifetime)
+	s.dirty = false
+	s.saved = time.Now()
+	s.mu.Unlock()
+
+	err := writeFileAtomic(s.file, data)
+	if err != nil {
+		s.mu.Lock()
+		s.current.Errors++
+		s.dirty = true // retry with the next save
+		s.mu.Unlock()
+	}
+	return err
+}
+
+// load reads lifetime counts, missing or broken file means starting from zero
+func (s *stats) load() {
+	if s.loaded {
+		return
+	}
+	s.loaded = true
+	s.lifetime = map[string]uint64{}
+	if s.file == "" {
+		return
+	}
+	if data, err := ioutil.ReadFile(s.file); err == nil {
+		json.Unmarshal(data, &s.lifetime)
+	}
+}
+
+func (s *stats) snapshot() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load()
+	snap := s.current
+	snap.Refreshes = map[string]uint64{}
+	snap.BusyTime = map[string]time.Duration{}
+	snap.LifetimeRefreshes = map[string]uint64{}
+	for mode, n := range s.current.Refreshes {
+		snap.Refreshes[mode] = n
+		snap.BusyTime[mode] = s.current.BusyTime[mode]
+	}
+	for mode, n := range s.lifetime {
+		snap.LifetimeRefreshes[mode] = n
+	}
+	return snap
+}
+
+// StatsHandler serves device telemetry in Prometheus text format
+func (d *Device) StatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := d.Stats()
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+		metric := func(name, kind, help string) {
+			fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		}
+		byMode := func(name string, values map[string]float64) {
+			modes := []string{}
+			for mode := range values {
+				modes = append(modes, mode)
+			}
+			sort.Strings(modes)
+			for _, mode := range modes {
+				fmt.Fprintf(w, "%s{mode=%q} %g\n", name, mode, values[mode])
+			}
+		}
+		refreshes := map[string]float64{}
+		lifetime := map[string]float64{}
+		busy := map[string]float64{}
+		for mode, n := range s.Refreshes {
+			refreshes[mode] = float64(n)
+			busy[mode] = s.BusyTime[mode].Seconds()
+		}
+		for mode, n := range s.LifetimeRefreshes {
+			lifetime[mode] = float64(n)
+		}
+
+		metric("epaper_refreshes_total", "counter", "Number of refreshes since start.")
+		byMode("epaper_refreshes_total", refreshes)
+		metric("epaper_lifetime_refreshes_total", "counter", "Number of refreshes during the lifetime of the panel.")
+		byMode("epaper_lifetime_refreshes_total", lifetime)
+		metric("epaper_busy_seconds_total", "counter", "Total duration of refreshes.")
+		byMode("epaper_busy_seconds_total", busy)
+		metric("epaper_last_busy_seconds", "gauge", "Duration of the last refresh.")
+		fmt.Fprintf(w, "epaper_last_busy_seconds %g\n", s.LastBusy.Seconds())
+		metric("epaper_sent_bytes_total", "counter", "Number of bytes sent to the controller.")
+		fmt.Fprintf(w, "epaper_sent_bytes_total %d\n", s.BytesSent)
+		metric("epaper_errors_total", "counter", "Number of failed operations.")
+		fmt.Fprintf(w, "epaper_errors_total %d\n", s.Errors)
+	})
+}
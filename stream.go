@@ -0,0 +1,53 @@
+package epaper
+
+// length of single Data call for transports without own limit
+const defaultMaxTransfer = 4096
+
+// MaxTransferer is implemented by transports
+// which limit the length of data sent by single Data call
+type MaxTransferer interface {
+	MaxTransfer() int
+}
+
+func (d *Device) maxTransfer() int {
+	if t, ok := d.transport.(MaxTransferer); ok && t.MaxTransfer() > 0 {
+		return t.MaxTransfer()
+	}
+	return defaultMaxTransfer
+}
+
+// chunk returns buffer reused for data transfers
+func (d *Device) chunk() []byte {
+	if n := d.maxTransfer(); len(d.buf) != n {
+		d.buf = make([]byte, n)
+		d.pending = 0
+	}
+	return d.buf
+}
+
+// streamData queues data to be sent in chunks as large as transport allows,
+// call flushData to send the rest
+func (d *Device) streamData(data []byte) {
+	chunk := d.chunk()
+	for len(data) > 0 {
+		if d.pending == 0 && len(data) >= len(chunk) { // send directly without copying
+			d.sendData(data[:len(chunk)]...)
+			data = data[len(chunk):]
+			continue
+		}
+		n := copy(chunk[d.pending:], data)
+		d.pending += n
+		data = data[n:]
+		if d.pending == len(chunk) {
+			d.flushData()
+		}
+	}
+}
+
+// flushData sends data queued by streamData
+func (d *Device) flushData() {
+	if d.pending > 0 {
+		d.sendData(d.buf[:d.pending]...)
+		d.pending = 0
+	}
+}
@@ -0,0 +1,164 @@
+package epaper
+
+import (
+	"github.com/stianeikeland/go-rpio"
+	"time"
+)
+
+// Transport is the bus connecting the controller with the host
+type Transport interface {
+	// Command sends command byte (DC pin low)
+	Command(cmd byte) error
+	// Data sends data bytes (DC pin high)
+	Data(data []byte) error
+	// Busy reports whether the controller is busy
+	Busy() (bool, error)
+	// Reset does hardware reset of the controller
+	Reset() error
+}
+
+// Reader is implemented by transports
+// which are able to read data from the controller over bidirectional SPI
+type Reader interface {
+	ReadData(data []byte) error
+}
+
+// SPIConfigurer is implemented by transports
+// which are able to change SPI clock frequency and mode,
+// zero speed keeps the current clock
+type SPIConfigurer interface {
+	ConfigureSPI(speed uint32, mode uint8) error
+}
+
+// WithSPI overrides SPI clock frequency and mode given by Module,
+// they are applied to the transport on every Init.
+// Zero speed keeps the speed of Module, so mode can be changed alone.
+func WithSPI(spi SPI) Option {
+	return func(d *Device) {
+		if spi.SPEED == 0 {
+			spi.SPEED = d.SPI.SPEED
+		}
+		d.SPI = spi
+	}
+}
+
+// WithTransport sets the bus used to communicate with the controller.
+// Default is Raspberry Pi GPIO and SPI0 set up by Setup.
+func WithTransport(t Transport) Option {
+	return func(d *Device) {
+		d.transport = t
+	}
+}
+
+// rpioTransport uses pins and SPI opened by Setup
+type rpioTransport struct {
+	buf []byte // SpiExchange overwrites sent data with received one
+}
+
+func (*rpioTransport) Command(cmd byte) error {
+	SendCommand(cmd)
+	return nil
+}
+
+func (t *rpioTransport) Data(data []byte) error {
+	if cap(t.buf) < len(data) {
+		t.buf = make([]byte, len(data))
+	}
+	buf := t.buf[:len(data)]
+	copy(buf, data)
+	dcPin.High()
+	rpio.SpiExchange(buf)
+	return nil
+}
+
+func (*rpioTransport) ConfigureSPI(speed uint32, mode uint8) error {
+	if speed > 0 {
+		rpio.SpiSpeed(int(speed))
+	}
+	rpio.SpiMode(mode>>1&1, mode&1)
+	return nil
+}
+
+func (*rpioTransport) MaxTransfer() int {
+	return defaultMaxTransfer
+}
+
+func (*rpioTransport) Busy() (bool, error) {
+	return busyPin.Read() == rpio.High, nil // doc say Low == busy, but it is the oposite
+}
+
+func (*rpioTransport) Reset() error {
+	Reset()
+	return nil
+}
+
+// Err returns error of the last operation of the device
+func (d *Device) Err() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.err
+}
+
+// lock acquires the bus for new operation
+func (d *Device) lock() {
+	d.mu.Lock()
+	d.err = nil
+}
+
+// sendCommand, sendData and reset remember the first error
+// and do nothing after it until the next operation
+
+func (d *Device) sendCommand(cmd byte) {
+	if d.err == nil {
+		d.fail(d.transport.Command(cmd))
+		d.stats.sent(1)
+	}
+}
+
+func (d *Device) sendData(data ...byte) {
+	if d.err == nil {
+		d.fail(d.transport.Data(data))
+		d.stats.sent(len(data))
+	}
+}
+
+func (d *Device) configureSPI() {
+	t, ok := d.transport.(SPIConfigurer)
+	if ok && d.err == nil {
+		d.fail(t.ConfigureSPI(d.SPI.SPEED, d.SPI.MODE))
+	}
+}
+
+func (d *Device) reset() {
+	if d.err == nil {
+		d.fail(d.transport.Reset())
+	}
+}
+
+func (d *Device) waitUntilIdle() {
+	if d.err == nil {
+		start := time.Now()
+		err := waitUntilIdle(d.transport)
+		d.logBusy(start, err)
+		d.fail(err)
+	}
+}
+
+// fail remembers error as the error of current operation
+func (d *Device) fail(err error) {
+	if err != nil && d.err == nil {
+		d.err = err
+		d.stats.failed()
+		d.log.Error("epaper: operation failed", "err", err)
+	}
+}
+
+func waitUntilIdle(t Transport) error {
+	for {
+		busy, err := t.Busy()
+		if err != nil || !busy {
+			return err
+		}
+		time.Sleep(time.Millisecond * 50)
+	}
+}